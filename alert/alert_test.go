@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRule(t *testing.T) {
+	t.Run("Parse a threshold rule with a duration.", func(t *testing.T) {
+		rule, err := ParseRule("memory.usage_pct > 90 for 30s")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "memory.usage_pct", rule.Metric)
+		assert.Equal(t, GreaterThan, rule.Operator)
+		assert.Equal(t, 90.0, rule.Threshold)
+		assert.Equal(t, 30*time.Second, rule.Duration)
+	})
+
+	t.Run("Parse a change rule.", func(t *testing.T) {
+		rule, err := ParseRule("oom_kill increased")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Increased, rule.Operator)
+	})
+
+	t.Run("Reject invalid rules.", func(t *testing.T) {
+		for _, expression := range []string{"memory", "unknown > 1", "memory.usage ~ 1", "memory.usage > x",
+			"memory.usage > 1 for", "oom_kill increased for 1s"} {
+			_, err := ParseRule(expression)
+
+			assert.Error(t, err, expression)
+		}
+	})
+}
+
+func TestEvaluator(t *testing.T) {
+	// Given
+	start := time.Now()
+	sample := func(seconds int, usage uint64, oomKills uint64) []*common.CgroupSnapshot {
+		return []*common.CgroupSnapshot{{
+			Name:      "/a.service",
+			Timestamp: start.Add(time.Duration(seconds) * time.Second),
+			Memory:    &common.MemorySnapshot{Usage: usage, Limit: 100},
+			Events:    &common.EventSnapshot{OomKills: oomKills},
+		}}
+	}
+	memoryRule, _ := ParseRule("memory.usage_pct > 90 for 30s")
+	oomRule, _ := ParseRule("oom_kill increased")
+	evaluator := NewEvaluator([]*Rule{memoryRule, oomRule})
+
+	// When / Then
+	assert.Empty(t, evaluator.Evaluate(sample(0, 95, 0)))
+	assert.Empty(t, evaluator.Evaluate(sample(20, 95, 0)))
+	alerts := evaluator.Evaluate(sample(30, 95, 1))
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "/a.service", alerts[0].Cgroup)
+	assert.Equal(t, 95.0, alerts[0].Value)
+	assert.Len(t, evaluator.Evaluate(sample(40, 95, 2)), 1, "A breach only fires once, while every change fires.")
+	assert.Empty(t, evaluator.Evaluate(sample(50, 50, 2)))
+	assert.Empty(t, evaluator.Evaluate(sample(60, 95, 2)), "The duration restarts once the condition stops holding.")
+}
+
+func TestNotifier(t *testing.T) {
+	alert := &Alert{
+		Rule:      &Rule{Expression: "oom_kill increased", Metric: "oom_kill"},
+		Cgroup:    "/a.service",
+		Value:     1,
+		Timestamp: time.Unix(0, 0),
+	}
+
+	t.Run("Run the hook with the alert in its environment.", func(t *testing.T) {
+		var output bytes.Buffer
+		notifier := NewNotifier(&output, "echo $CGSTAT_ALERT_CGROUP")
+
+		notifier.Notify(alert)
+		notifier.Wait()
+
+		assert.Contains(t, output.String(), "ALERT oom_kill increased: /a.service")
+		assert.Contains(t, output.String(), "/a.service\n")
+	})
+
+	t.Run("Kill a hook which does not finish in time without blocking.", func(t *testing.T) {
+		var output bytes.Buffer
+		notifier := NewNotifier(&output, "sleep 10")
+		notifier.hookTimeout = 100 * time.Millisecond
+
+		start := time.Now()
+		notifier.Notify(alert)
+		assert.Less(t, time.Since(start), 100*time.Millisecond, "Notify should not wait for the hook.")
+		notifier.Wait()
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Contains(t, output.String(), "alert hook timed out after 100ms for /a.service")
+	})
+}
@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// Alert is fired when a cgroup satisfies a rule.
+type Alert struct {
+	// Timestamp is the time of the sample which fired the alert.
+	Timestamp time.Time
+	Rule      *Rule
+	Cgroup    string
+	Value     float64
+}
+
+// ruleState tracks a rule for a single cgroup between samples.
+type ruleState struct {
+	previous float64
+	// since is the time of the first sample of the current streak of matching samples, or zero if the last sample
+	// did not match.
+	since time.Time
+	// fired is true once the current streak has fired an alert, so that each breach only fires once.
+	fired bool
+}
+
+// Evaluator evaluates a set of rules against each sample of cgroup stats.
+type Evaluator struct {
+	rules []*Rule
+	// states are keyed by the index of the rule, then by cgroup name.
+	states []map[string]*ruleState
+}
+
+func NewEvaluator(rules []*Rule) *Evaluator {
+	states := make([]map[string]*ruleState, len(rules))
+	for i := range states {
+		states[i] = map[string]*ruleState{}
+	}
+	return &Evaluator{
+		rules:  rules,
+		states: states,
+	}
+}
+
+// Evaluate returns the alerts fired by the given sample. A threshold rule fires once for a cgroup when its condition has
+// held for the duration of the rule, and again only after the condition stopped holding. A change rule, such as
+// "oom_kill increased", fires on every sample in which the value changed. The state of cgroups which are not in the
+// sample is discarded.
+func (e *Evaluator) Evaluate(snapshots []*common.CgroupSnapshot) []*Alert {
+	var alerts []*Alert
+	for i, rule := range e.rules {
+		states := make(map[string]*ruleState, len(snapshots))
+		for _, snapshot := range snapshots {
+			timestamp := snapshot.Timestamp
+			if timestamp.IsZero() {
+				timestamp = time.Now()
+			}
+			value := rule.Field.Value(snapshot)
+
+			state, hasPrevious := e.states[i][snapshot.Name]
+			if !hasPrevious {
+				state = &ruleState{}
+			}
+			states[snapshot.Name] = state
+
+			if !rule.matches(value, state.previous, hasPrevious) {
+				state.since = time.Time{}
+				state.fired = false
+			} else {
+				if state.since.IsZero() {
+					state.since = timestamp
+				}
+				if !state.fired && timestamp.Sub(state.since) >= rule.Duration {
+					state.fired = !rule.isChangeRule()
+					alerts = append(alerts, &Alert{
+						Timestamp: timestamp,
+						Rule:      rule,
+						Cgroup:    snapshot.Name,
+						Value:     value,
+					})
+				}
+			}
+			state.previous = value
+		}
+		e.states[i] = states
+	}
+	return alerts
+}
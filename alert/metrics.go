@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"sort"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+var (
+	memoryUsagePercentField = &common.SnapshotField{
+		Name: "memory.usage_pct",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.Memory == nil {
+				return 0
+			}
+			return s.Memory.UsagePercent()
+		},
+	}
+	cpuCoresField = &common.SnapshotField{
+		Name: "cpu.cores",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.CPU == nil {
+				return 0
+			}
+			return s.CPU.Utilization / 100.0
+		},
+	}
+	cpuLimitPercentField = &common.SnapshotField{
+		Name: "cpu.limit_pct",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.CPU == nil {
+				return 0
+			}
+			return s.CPU.Limit.UsagePercent(s.CPU.Utilization)
+		},
+	}
+	cpuThrottledPercentField = &common.SnapshotField{
+		Name: "cpu.throttled_pct",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.CPU == nil || s.CPU.Throttling == nil {
+				return 0
+			}
+			return s.CPU.Throttling.ThrottledPercent
+		},
+	}
+	pageFaultRateField      = rateField("memory.page_faults_rate", common.PageFaultsCounter)
+	majorPageFaultRateField = rateField("memory.major_page_faults_rate", common.MajorPageFaultsCounter)
+	fdsLimitPercentField    = &common.SnapshotField{
+		Name: "fds.limit_pct",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.Proc == nil {
+				return 0
+			}
+			return s.Proc.FDLimitPercent
+		},
+	}
+	pidsUsagePercentField = &common.SnapshotField{
+		Name: "pids.usage_pct",
+		Value: func(s *common.CgroupSnapshot) float64 {
+			if s.PIDs == nil || s.PIDs.Limit == 0 {
+				return 0
+			}
+			return float64(s.PIDs.Current) / float64(s.PIDs.Limit) * 100.0
+		},
+	}
+)
+
+// rateField returns a field with the per-second rate of the given counter since the previous sample.
+func rateField(name string, counter string) *common.SnapshotField {
+	return &common.SnapshotField{
+		Name: name,
+		Value: func(s *common.CgroupSnapshot) float64 {
+			return s.Rates[counter]
+		},
+	}
+}
+
+// Metrics contains every stat which can be used in an alert rule, keyed by its dotted name.
+var Metrics = map[string]*common.SnapshotField{
+	"cpu.utilization":               common.CPUField,
+	"cpu.cores":                     cpuCoresField,
+	"cpu.limit_pct":                 cpuLimitPercentField,
+	"cpu.usage_usec":                common.CPUUsageField,
+	"cpu.throttled_periods":         common.ThrottledPeriodsField,
+	"cpu.throttled_pct":             cpuThrottledPercentField,
+	"memory.usage":                  common.MemoryField,
+	"memory.usage_pct":              memoryUsagePercentField,
+	"memory.page_faults":            common.PageFaultsField,
+	"memory.major_page_faults":      common.MajorPageFaultsField,
+	"memory.page_faults_rate":       pageFaultRateField,
+	"memory.major_page_faults_rate": majorPageFaultRateField,
+	"pids.current":                  common.PIDsField,
+	"pids.usage_pct":                pidsUsagePercentField,
+	"oom_kill":                      common.OomKillsField,
+	"fds":                           common.FDsField,
+	"fds.limit_pct":                 fdsLimitPercentField,
+	"sockets":                       common.SocketsField,
+	"io.read_bytes":                 common.IOReadBytesField,
+	"io.write_bytes":                common.IOWriteBytesField,
+}
+
+// MetricNames returns the names of every metric, in alphabetical order.
+func MetricNames() []string {
+	names := make([]string, 0, len(Metrics))
+	for name := range Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,93 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HookTimeout is how long an alert hook may run before it is killed.
+const HookTimeout = 30 * time.Second
+
+// Notifier reports fired alerts by writing them to a writer, usually stderr, and by running an optional hook command.
+type Notifier struct {
+	// mu serializes writes to the writer, which hooks write to once they finish.
+	mu     sync.Mutex
+	writer io.Writer
+	// hook is a shell command which is run for each alert, with the details of the alert in its environment.
+	hook        string
+	hookTimeout time.Duration
+	// hooks tracks the hooks which are still running.
+	hooks sync.WaitGroup
+}
+
+func NewNotifier(writer io.Writer, hook string) *Notifier {
+	return &Notifier{
+		writer:      writer,
+		hook:        hook,
+		hookTimeout: HookTimeout,
+	}
+}
+
+// Notify reports the given alert. The hook is run in the background, so that a slow hook does not delay sampling. A
+// failing hook is reported to the writer, and does not prevent other alerts from being reported.
+func (n *Notifier) Notify(alert *Alert) {
+	timestamp := alert.Timestamp.UTC().Format(time.RFC3339)
+	n.mu.Lock()
+	_, _ = fmt.Fprintf(n.writer, "%s ALERT %s: %s (%s = %s)\n", timestamp, alert.Rule.Expression, alert.Cgroup,
+		alert.Rule.Metric, formatValue(alert.Value))
+	n.mu.Unlock()
+
+	if n.hook == "" {
+		return
+	}
+	n.hooks.Add(1)
+	go func() {
+		defer n.hooks.Done()
+		n.runHook(alert, timestamp)
+	}()
+}
+
+// Wait waits for every running hook to finish.
+func (n *Notifier) Wait() {
+	n.hooks.Wait()
+}
+
+// runHook runs the hook for the given alert, and writes its output to the writer once it finishes. The hook is killed
+// if it runs for longer than the hook timeout.
+func (n *Notifier) runHook(alert *Alert, timestamp string) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.hook)
+	cmd.Env = append(os.Environ(),
+		"CGSTAT_ALERT_TIME="+timestamp,
+		"CGSTAT_ALERT_RULE="+alert.Rule.Expression,
+		"CGSTAT_ALERT_METRIC="+alert.Rule.Metric,
+		"CGSTAT_ALERT_CGROUP="+alert.Cgroup,
+		"CGSTAT_ALERT_VALUE="+formatValue(alert.Value),
+	)
+	// Processes started by the hook may keep its output open after the hook is killed.
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, _ = n.writer.Write(output)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		_, _ = fmt.Fprintf(n.writer, "%s alert hook timed out after %s for %s\n", timestamp, n.hookTimeout,
+			alert.Cgroup)
+	} else if err != nil {
+		_, _ = fmt.Fprintf(n.writer, "%s alert hook failed for %s: %s\n", timestamp, alert.Cgroup, err)
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
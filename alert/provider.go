@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// AlertingCgroupStatsProvider is a CgroupStatsProvider which evaluates rules against every collection of stats
+// returned by the given provider, before they are sorted, filtered or aggregated.
+type AlertingCgroupStatsProvider struct {
+	common.CgroupStatsProvider
+	evaluator *Evaluator
+	notifier  *Notifier
+	// fired is true once any rule has fired.
+	fired bool
+}
+
+func NewAlertingCgroupStatsProvider(provider common.CgroupStatsProvider, evaluator *Evaluator,
+	notifier *Notifier) *AlertingCgroupStatsProvider {
+	return &AlertingCgroupStatsProvider{
+		CgroupStatsProvider: provider,
+		evaluator:           evaluator,
+		notifier:            notifier,
+	}
+}
+
+func (a *AlertingCgroupStatsProvider) GetCgroupStatsByPrefix(prefix string) (common.CgroupStatsCollection, error) {
+	return a.evaluate(a.CgroupStatsProvider.GetCgroupStatsByPrefix(prefix))
+}
+
+func (a *AlertingCgroupStatsProvider) GetCgroupStatsByName(name string) (common.CgroupStatsCollection, error) {
+	return a.evaluate(a.CgroupStatsProvider.GetCgroupStatsByName(name))
+}
+
+// Fired returns true if any rule has fired since the provider was created.
+func (a *AlertingCgroupStatsProvider) Fired() bool {
+	return a.fired
+}
+
+// Wait waits for the hooks of fired alerts to finish.
+func (a *AlertingCgroupStatsProvider) Wait() {
+	a.notifier.Wait()
+}
+
+func (a *AlertingCgroupStatsProvider) evaluate(collection common.CgroupStatsCollection, err error) (common.CgroupStatsCollection, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, alert := range a.evaluator.Evaluate(collection.ToSnapshots()) {
+		a.fired = true
+		a.notifier.Notify(alert)
+	}
+	return collection, nil
+}
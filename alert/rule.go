@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// Operator compares the current value of a metric to the threshold of a rule, or to its previous value.
+type Operator string
+
+const (
+	GreaterThan      Operator = ">"
+	GreaterThanEqual Operator = ">="
+	LessThan         Operator = "<"
+	LessThanEqual    Operator = "<="
+	Equal            Operator = "=="
+	NotEqual         Operator = "!="
+	// Increased matches when the value of a metric is greater than in the previous sample.
+	Increased Operator = "increased"
+	// Decreased matches when the value of a metric is less than in the previous sample.
+	Decreased Operator = "decreased"
+)
+
+var thresholdOperators = []Operator{GreaterThan, GreaterThanEqual, LessThan, LessThanEqual, Equal, NotEqual}
+
+// Rule is a condition on a metric of each cgroup, such as "memory.usage_pct > 90 for 30s" or "oom_kill increased".
+type Rule struct {
+	// Expression is the rule as it was written.
+	Expression string
+	Metric     string
+	Field      *common.SnapshotField
+	Operator   Operator
+	// Threshold is the value which the metric is compared to. It is unused by Increased and Decreased.
+	Threshold float64
+	// Duration is how long the condition must hold before the rule fires. Zero fires on the first matching sample.
+	Duration time.Duration
+}
+
+// ParseRule parses a rule of the form "<metric> <operator> <threshold> [for <duration>]" or
+// "<metric> increased|decreased".
+func ParseRule(expression string) (*Rule, error) {
+	tokens := strings.Fields(expression)
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("invalid rule %q, must be of the form \"<metric> <operator> <threshold> [for <duration>]\"",
+			expression)
+	}
+
+	field, ok := Metrics[tokens[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q in rule %q, must be one of: %s", tokens[0], expression,
+			strings.Join(MetricNames(), ", "))
+	}
+	rule := &Rule{
+		Expression: expression,
+		Metric:     tokens[0],
+		Field:      field,
+		Operator:   Operator(tokens[1]),
+	}
+
+	switch {
+	case rule.isChangeRule():
+		tokens = tokens[2:]
+	case isThresholdOperator(rule.Operator):
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("missing threshold in rule %q", expression)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSuffix(tokens[2], "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q in rule %q", tokens[2], expression)
+		}
+		rule.Threshold = threshold
+		tokens = tokens[3:]
+	default:
+		return nil, fmt.Errorf("unknown operator %q in rule %q", tokens[1], expression)
+	}
+
+	if len(tokens) == 0 {
+		return rule, nil
+	}
+	if rule.isChangeRule() {
+		return nil, fmt.Errorf("rule %q fires on every change, and cannot have a duration", expression)
+	}
+	if len(tokens) != 2 || tokens[0] != "for" {
+		return nil, fmt.Errorf("unexpected %q in rule %q", strings.Join(tokens, " "), expression)
+	}
+	duration, err := time.ParseDuration(tokens[1])
+	if err != nil || duration < 0 {
+		return nil, fmt.Errorf("invalid duration %q in rule %q", tokens[1], expression)
+	}
+	rule.Duration = duration
+	return rule, nil
+}
+
+func isThresholdOperator(operator Operator) bool {
+	for _, o := range thresholdOperators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// isChangeRule returns true if the rule compares each sample to the previous one, rather than to a threshold.
+func (r *Rule) isChangeRule() bool {
+	return r.Operator == Increased || r.Operator == Decreased
+}
+
+// matches returns true if the given value satisfies the condition of the rule. The previous value is only used by
+// Increased and Decreased, which never match the first sample of a cgroup.
+func (r *Rule) matches(value float64, previous float64, hasPrevious bool) bool {
+	switch r.Operator {
+	case GreaterThan:
+		return value > r.Threshold
+	case GreaterThanEqual:
+		return value >= r.Threshold
+	case LessThan:
+		return value < r.Threshold
+	case LessThanEqual:
+		return value <= r.Threshold
+	case Equal:
+		return value == r.Threshold
+	case NotEqual:
+		return value != r.Threshold
+	case Increased:
+		return hasPrevious && value > previous
+	case Decreased:
+		return hasPrevious && value < previous
+	}
+	return false
+}
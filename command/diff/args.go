@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ArgAll    = "all"
+	ArgFormat = "format"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+type Args struct {
+	// BeforeFile and AfterFile contain the samples to compare. Both are the same file when comparing the first and
+	// last samples of a single file.
+	BeforeFile string
+	AfterFile  string
+	All        bool
+	Format     string
+}
+
+func flags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  ArgAll,
+			Usage: "Also show cgroups which did not change.",
+		},
+		cli.StringFlag{
+			Name:  ArgFormat,
+			Usage: "Format of the output: table or json.",
+			Value: FormatTable,
+		},
+	}
+}
+
+func parseArgs(cCtx *cli.Context) (*Args, error) {
+	diffArgs := &Args{
+		BeforeFile: cCtx.Args().Get(0),
+		AfterFile:  cCtx.Args().Get(1),
+		All:        cCtx.Bool(ArgAll),
+		Format:     cCtx.String(ArgFormat),
+	}
+	if cCtx.NArg() == 1 {
+		diffArgs.AfterFile = diffArgs.BeforeFile
+	}
+
+	if err := validateArguments(cCtx.NArg(), diffArgs); err != nil {
+		return nil, fmt.Errorf("error parsing diff args: %s", err)
+	}
+
+	return diffArgs, nil
+}
+
+func validateArguments(numArgs int, args *Args) error {
+	if numArgs < 1 || numArgs > 2 {
+		return errors.New("you must specify either two files to compare, or a single file with multiple samples")
+	}
+	if args.Format != FormatTable && args.Format != FormatJSON {
+		return fmt.Errorf("unknown format %q, must be one of: %s, %s", args.Format, FormatTable, FormatJSON)
+	}
+	return nil
+}
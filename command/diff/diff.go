@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/rodaine/table"
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/urfave/cli"
+)
+
+func Register() cli.Command {
+	return cli.Command{
+		Name:      "diff",
+		Usage:     "Compare the stats of cgroups between two samples.",
+		UsageText: "cgstat diff [options] <before> [<after>]",
+		Description: "Compares the last sample of two files, or the first and last sample of a single file. Files are " +
+			"either recordings from view --record, or the output of view --format=json or --format=ndjson.",
+		Action: action,
+		Flags:  flags(),
+	}
+}
+
+func action(cCtx *cli.Context) error {
+	diffArgs, err := parseArgs(cCtx)
+	if err != nil {
+		return err
+	}
+
+	before, after, err := readSnapshots(diffArgs.BeforeFile)
+	if err != nil {
+		return err
+	}
+	if diffArgs.AfterFile != diffArgs.BeforeFile {
+		before = after
+		if _, after, err = readSnapshots(diffArgs.AfterFile); err != nil {
+			return err
+		}
+	}
+
+	diffs := []*common.CgroupDiff{}
+	for _, diff := range common.DiffSnapshots(before, after) {
+		if diffArgs.All || diff.Status != common.DiffUnchanged {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if diffArgs.Format == FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diffs)
+	}
+	printDiffs(diffs)
+	return nil
+}
+
+func printDiffs(diffs []*common.CgroupDiff) {
+	headers := []interface{}{"Name", "Status"}
+	for _, field := range common.DiffFields {
+		headers = append(headers, field.Name)
+	}
+
+	tbl := table.New(headers...)
+	for _, diff := range diffs {
+		row := []interface{}{common.Shorten(diff.Name, 32), diff.Status}
+		for i, delta := range diff.Deltas {
+			formatted := formatDelta(common.DiffFields[i], delta.Delta)
+			if delta.Reset {
+				formatted += " (reset)"
+			}
+			row = append(row, formatted)
+		}
+		tbl.AddRow(row...)
+	}
+	tbl.Print()
+}
+
+// formatDelta formats the change of a field with an explicit sign, so that increases and decreases stand out.
+func formatDelta(field *common.DiffField, delta float64) string {
+	if delta == 0 {
+		return "0"
+	}
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	switch {
+	case field.SnapshotField == common.CPUUsageField:
+		return fmt.Sprintf("%s%.2fs", sign, math.Abs(delta)/1e6)
+	case field.Bytes:
+		return sign + common.FormatBytes(uint64(math.Abs(delta)))
+	}
+	return fmt.Sprintf("%s%.0f", sign, math.Abs(delta))
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/strategicpause/cgstat/record"
+	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// gzipMagic are the first bytes of a gzip stream, which identify a recording.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readSnapshots returns the snapshots of the first and the last sample of the given file, which is either a recording,
+// or the output of view --format=json or --format=ndjson.
+func readSnapshots(filename string) ([]*common.CgroupSnapshot, []*common.CgroupSnapshot, error) {
+	first, last, err := readSamples(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	if first == nil {
+		return nil, nil, fmt.Errorf("%s does not contain any samples", filename)
+	}
+
+	firstSnapshots, err := toSnapshots(first)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read %s: %w", filename, err)
+	}
+	lastSnapshots, err := toSnapshots(last)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read %s: %w", filename, err)
+	}
+	return firstSnapshots, lastSnapshots, nil
+}
+
+func readSamples(filename string) (*record.Sample, *record.Sample, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(len(gzipMagic))
+	if err == nil && string(magic) == string(gzipMagic) {
+		_ = file.Close()
+		return readRecordedSamples(filename)
+	}
+
+	var first, last *record.Sample
+	decoder := json.NewDecoder(reader)
+	for {
+		sample := &record.Sample{}
+		if err = decoder.Decode(sample); err != nil {
+			if errors.Is(err, io.EOF) {
+				return first, last, nil
+			}
+			return nil, nil, fmt.Errorf("could not decode %s: %w", filename, err)
+		}
+		if first == nil {
+			first = sample
+		}
+		last = sample
+	}
+}
+
+func readRecordedSamples(filename string) (*record.Sample, *record.Sample, error) {
+	reader, err := record.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	var first, last *record.Sample
+	for {
+		sample, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return first, last, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if first == nil {
+			first = sample
+		}
+		last = sample
+	}
+}
+
+func toSnapshots(sample *record.Sample) ([]*common.CgroupSnapshot, error) {
+	collection, err := stats.DecodeCollection(sample.CgroupVersion, sample.Timestamp, sample.Cgroups)
+	if err != nil {
+		return nil, err
+	}
+	return collection.ToSnapshots(), nil
+}
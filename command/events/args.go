@@ -0,0 +1,61 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ArgPrefix = "prefix"
+	ArgFormat = "format"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type Args struct {
+	CgroupPrefix string
+	Format       string
+}
+
+func flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  ArgPrefix,
+			Usage: "Only watch cgroups with the given prefix.",
+			Value: "/",
+		},
+		cli.StringFlag{
+			Name:  ArgFormat,
+			Usage: "Format of each event: text, or json for one JSON document per line.",
+			Value: FormatText,
+		},
+	}
+}
+
+func parseArgs(cCtx *cli.Context) (*Args, error) {
+	eventsArgs := &Args{
+		CgroupPrefix: cCtx.String(ArgPrefix),
+		Format:       cCtx.String(ArgFormat),
+	}
+
+	if err := validateArguments(eventsArgs); err != nil {
+		return nil, fmt.Errorf("error parsing events args: %s", err)
+	}
+
+	return eventsArgs, nil
+}
+
+func validateArguments(args *Args) error {
+	if args.CgroupPrefix == "" {
+		return errors.New("cgroup prefix must be specified")
+	}
+	if args.Format != FormatText && args.Format != FormatJSON {
+		return fmt.Errorf("unknown format %q, must be one of: %s, %s", args.Format, FormatText, FormatJSON)
+	}
+	return nil
+}
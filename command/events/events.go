@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats"
+	v2 "github.com/strategicpause/cgstat/stats/v2"
+	"github.com/urfave/cli"
+)
+
+func Register() cli.Command {
+	return cli.Command{
+		Name:  "events",
+		Usage: "Print memory, pids and cgroup events as they happen.",
+		Description: "Watches memory.events, pids.events and cgroup.events of every cgroup with the given prefix, " +
+			"including cgroups created while it runs, and prints a line as soon as one of their values changes, such " +
+			"as oom_kill, max, high, populated or frozen. Requires cgroup v2.",
+		Action: action,
+		Flags:  flags(),
+	}
+}
+
+func action(cCtx *cli.Context) error {
+	eventsArgs, err := parseArgs(cCtx)
+	if err != nil {
+		return err
+	}
+	if !stats.IsCgroupsV2Enabled() {
+		return errors.New("the events command requires cgroup v2")
+	}
+
+	watcher, err := v2.NewEventWatcher(v2.CgroupPrefix, eventsArgs.CgroupPrefix)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if eventsArgs.Format == FormatText {
+		fmt.Fprintf(os.Stderr, "Watching %d events files\n", watcher.NumWatchedFiles())
+	}
+	return watchEvents(os.Stdout, watcher, eventsArgs.Format)
+}
+
+// watchEvents writes each event of the watcher to the given writer in the given format, until the watcher is closed
+// or an event cannot be written, such as when stdout is a pipe which was closed.
+func watchEvents(w io.Writer, watcher *v2.EventWatcher, format string) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		return watcher.Run(func(event *v2.CgroupEvent) error {
+			if err := encoder.Encode(event); err != nil {
+				return fmt.Errorf("could not write event: %w", err)
+			}
+			return nil
+		})
+	}
+	return watcher.Run(func(event *v2.CgroupEvent) error {
+		return printEvent(w, event)
+	})
+}
+
+func printEvent(w io.Writer, event *v2.CgroupEvent) error {
+	change := fmt.Sprintf("%d -> %d", event.Previous, event.Value)
+	if event.Value > event.Previous && event.File != v2.CgroupEventsFile {
+		change = fmt.Sprintf("%d (+%d)", event.Value, event.Value-event.Previous)
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s %s %s\n", event.Timestamp.UTC().Format(time.RFC3339Nano), event.Cgroup,
+		event.File, event.Key, change)
+	if err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	v2 "github.com/strategicpause/cgstat/stats/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchEvents(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			// Given
+			rootDir := t.TempDir()
+			cgroupDir := filepath.Join(rootDir, "system.slice", "a.service")
+			assert.NoError(t, os.MkdirAll(cgroupDir, 0755))
+			memoryEventsPath := filepath.Join(cgroupDir, v2.MemoryEventsFile)
+			assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("oom 0\noom_kill 0\n"), 0644))
+
+			watcher, err := v2.NewEventWatcher(rootDir, "/system.slice")
+			assert.NoError(t, err)
+			defer watcher.Close()
+
+			reader, writer := io.Pipe()
+			errs := make(chan error, 1)
+			go func() {
+				errs <- watchEvents(writer, watcher, format)
+			}()
+			lines := make(chan string, 10)
+			go func() {
+				scanner := bufio.NewScanner(reader)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+
+			// When
+			assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("oom 0\noom_kill 2\n"), 0644))
+
+			// Then
+			line := receive(t, lines)
+			if format == FormatJSON {
+				var event v2.CgroupEvent
+				assert.NoError(t, json.Unmarshal([]byte(line), &event))
+				assert.Equal(t, "/system.slice/a.service", event.Cgroup)
+				assert.Equal(t, "oom_kill", event.Key)
+				assert.Equal(t, uint64(2), event.Value)
+			} else {
+				assert.Regexp(t, regexp.MustCompile(`^\S+Z /system.slice/a.service memory.events oom_kill 2 \(\+2\)$`), line)
+			}
+
+			// When
+			assert.NoError(t, reader.Close())
+			assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("oom 0\noom_kill 3\n"), 0644))
+
+			// Then
+			select {
+			case err := <-errs:
+				assert.ErrorContains(t, err, "could not write event", "Write errors should stop watching.")
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected watching to stop once events could not be written")
+			}
+		})
+	}
+}
+
+func receive(t *testing.T, lines <-chan string) string {
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event to be written")
+		return ""
+	}
+}
@@ -3,7 +3,9 @@ package list
 import "github.com/urfave/cli"
 
 const (
-	ArgsPrefix = "prefix"
+	ArgsPrefix   = "prefix"
+	ArgsTree     = "tree"
+	ArgsMaxDepth = "max-depth"
 )
 
 func flags() []cli.Flag {
@@ -13,5 +15,14 @@ func flags() []cli.Flag {
 			Usage: "Cgroup prefix",
 			Value: "/",
 		},
+		cli.BoolFlag{
+			Name:  ArgsTree,
+			Usage: "Display cgroups as a tree, indented by their depth in the hierarchy.",
+		},
+		cli.IntFlag{
+			Name:  ArgsMaxDepth,
+			Usage: "Maximum depth of the tree to display, where 0 only shows the top-level cgroups. Unlimited if negative.",
+			Value: -1,
+		},
 	}
 }
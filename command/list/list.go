@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/stats/common"
 
 	"github.com/urfave/cli"
 )
@@ -24,8 +25,23 @@ func action(cCtx *cli.Context) error {
 	prefix := cCtx.String(ArgsPrefix)
 	cgroups := provider.ListCgroupsByPrefix(prefix)
 
+	if cCtx.Bool(ArgsTree) {
+		printTree(cgroups, cCtx.Int(ArgsMaxDepth))
+		return nil
+	}
+
 	for _, cgroup := range cgroups {
 		fmt.Println(cgroup)
 	}
 	return nil
 }
+
+func printTree(cgroups []string, maxDepth int) {
+	entries := common.BuildTree(cgroups, func(i, j int) bool {
+		return cgroups[i] < cgroups[j]
+	}, maxDepth, nil)
+
+	for _, entry := range entries {
+		fmt.Println(common.TreeDisplayName(entry))
+	}
+}
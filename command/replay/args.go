@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ArgSpeed   = "speed"
+	ArgVerbose = "verbose"
+	ArgOut     = "out"
+	ArgFormat  = "format"
+)
+
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+type Args struct {
+	RecordFile    string
+	Speed         float64
+	VerboseOutput bool
+	OutputFile    string
+	Format        string
+}
+
+func flags() []cli.Flag {
+	return []cli.Flag{
+		cli.Float64Flag{
+			Name:  ArgSpeed,
+			Usage: "Playback speed relative to the original recording, where 2 plays twice as fast. 0 plays every sample without waiting.",
+			Value: 1.0,
+		},
+		cli.BoolFlag{
+			Name:  ArgVerbose,
+			Usage: "Prints verbose information about each cgroup.",
+		},
+		cli.StringFlag{
+			Name:  ArgOut,
+			Usage: "Writes to a given CSV file if provided.",
+		},
+		cli.StringFlag{
+			Name:  ArgFormat,
+			Usage: "Format of the output printed to the screen: table, json or ndjson.",
+			Value: FormatTable,
+		},
+	}
+}
+
+func parseArgs(cCtx *cli.Context) (*Args, error) {
+	replayArgs := &Args{
+		RecordFile:    cCtx.Args().First(),
+		Speed:         cCtx.Float64(ArgSpeed),
+		VerboseOutput: cCtx.Bool(ArgVerbose),
+		OutputFile:    cCtx.String(ArgOut),
+		Format:        cCtx.String(ArgFormat),
+	}
+
+	if err := validateArguments(replayArgs); err != nil {
+		return nil, fmt.Errorf("error parsing replay args: %s", err)
+	}
+
+	return replayArgs, nil
+}
+
+func validateArguments(args *Args) error {
+	if args.RecordFile == "" {
+		return errors.New("a recording must be specified")
+	}
+	if args.Speed < 0.0 {
+		return errors.New("you must specify a non-negative speed")
+	}
+	if args.Format != FormatTable && args.Format != FormatJSON && args.Format != FormatNDJSON {
+		return fmt.Errorf("unknown format %q, must be one of: %s, %s, %s", args.Format, FormatTable, FormatJSON,
+			FormatNDJSON)
+	}
+	if args.VerboseOutput && args.Format != FormatTable {
+		return errors.New("verbose output is only supported by the table format")
+	}
+	if args.HasOutputFile() {
+		base, err := filepath.Abs(args.OutputFile)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(filepath.Dir(base))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Args) IsTableFormat() bool {
+	return a.Format == FormatTable
+}
+
+func (a *Args) HasOutputFile() bool {
+	return a.OutputFile != ""
+}
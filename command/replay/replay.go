@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/strategicpause/cgstat/record"
+	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/writer"
+	"github.com/urfave/cli"
+)
+
+func Register() cli.Command {
+	return cli.Command{
+		Name:      "replay",
+		Usage:     "Play back a recording made with view --record.",
+		UsageText: "cgstat replay [options] <recording>",
+		Action:    action,
+		Flags:     flags(),
+	}
+}
+
+func action(cCtx *cli.Context) error {
+	replayArgs, err := parseArgs(cCtx)
+	if err != nil {
+		return err
+	}
+
+	reader, err := record.Open(replayArgs.RecordFile)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	cmd := Command{
+		reader:      reader,
+		writers:     getWriters(replayArgs),
+		speed:       replayArgs.Speed,
+		clearScreen: replayArgs.IsTableFormat(),
+	}
+	return cmd.Run()
+}
+
+func getWriters(args *Args) []writer.StatsWriter {
+	var options []writer.ViewWriterOptions
+
+	if args.HasOutputFile() {
+		options = append(options, writer.WithCSVWriter(args.OutputFile))
+	}
+
+	switch args.Format {
+	case FormatJSON:
+		options = append(options, writer.WithJSONWriter())
+	case FormatNDJSON:
+		options = append(options, writer.WithNDJSONWriter())
+	default:
+		displayVerbosity := writer.Normal
+		if args.VerboseOutput {
+			displayVerbosity = writer.Verbose
+		}
+		options = append(options, writer.WithDisplayWriter(displayVerbosity))
+	}
+
+	return writer.NewViewWriters(options)
+}
+
+type Command struct {
+	reader  *record.Reader
+	writers []writer.StatsWriter
+	// speed is the playback speed relative to the recording, where 0 plays every sample without waiting.
+	speed       float64
+	clearScreen bool
+}
+
+func (c *Command) Run() error {
+	var previous time.Time
+	for {
+		sample, err := c.reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !previous.IsZero() && c.speed > 0 {
+			time.Sleep(time.Duration(float64(sample.Timestamp.Sub(previous)) / c.speed))
+		}
+		previous = sample.Timestamp
+
+		if err = c.writeSample(sample); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Command) writeSample(sample *record.Sample) error {
+	collection, err := stats.DecodeCollection(sample.CgroupVersion, sample.Timestamp, sample.Cgroups)
+	if err != nil {
+		return err
+	}
+	if c.clearScreen {
+		// Clear Screen
+		fmt.Print("\033[H\033[2J")
+	}
+	for _, w := range c.writers {
+		if err = w.Write(collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ArgPrefix        = "prefix"
+	ArgListenAddress = "listen-address"
+	ArgDevice        = "device"
+	ArgConcurrency   = "concurrency"
+)
+
+type Args struct {
+	CgroupPrefix  string
+	ListenAddress string
+	Device        string
+	Concurrency   int
+}
+
+func flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  ArgPrefix,
+			Usage: "Only export stats for cgroups with the given prefix.",
+			Value: "/",
+		},
+		cli.StringFlag{
+			Name:  ArgListenAddress,
+			Usage: "Address on which to expose metrics.",
+			Value: ":9753",
+		},
+		cli.StringFlag{
+			Name:  ArgDevice,
+			Usage: "Only export IO stats for the given block device name (ex: nvme0n1p2) or major:minor number.",
+		},
+		cli.IntFlag{
+			Name:  ArgConcurrency,
+			Usage: "Maximum number of cgroups whose stats are collected at the same time. Defaults to the number of CPUs.",
+		},
+	}
+}
+
+func parseArgs(cCtx *cli.Context) (*Args, error) {
+	serveArgs := &Args{
+		CgroupPrefix:  cCtx.String(ArgPrefix),
+		ListenAddress: cCtx.String(ArgListenAddress),
+		Device:        cCtx.String(ArgDevice),
+		Concurrency:   cCtx.Int(ArgConcurrency),
+	}
+
+	if err := validateArguments(serveArgs); err != nil {
+		return nil, fmt.Errorf("error parsing serve args: %s", err)
+	}
+
+	return serveArgs, nil
+}
+
+func validateArguments(args *Args) error {
+	if args.CgroupPrefix == "" {
+		return errors.New("cgroup prefix must be specified")
+	}
+	if args.ListenAddress == "" {
+		return errors.New("listen address must be specified")
+	}
+	if args.Concurrency < 0 {
+		return errors.New("concurrency must not be negative")
+	}
+	return nil
+}
+
+func (a *Args) HasDevice() bool {
+	return a.Device != ""
+}
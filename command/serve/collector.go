@@ -0,0 +1,69 @@
+package serve
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	MetricNamespace = "cgstat"
+	CgroupLabel     = "cgroup"
+)
+
+// CgroupStatsCollector is an implementation of prometheus.Collector which collects stats for all cgroups with a given
+// prefix each time it is scraped.
+type CgroupStatsCollector struct {
+	// mu serializes scrapes, since rates are computed from the previous sample of the provider, which concurrent
+	// scrapes would interleave.
+	mu       sync.Mutex
+	provider common.CgroupStatsProvider
+	prefix   string
+}
+
+func NewCgroupStatsCollector(provider common.CgroupStatsProvider, prefix string) *CgroupStatsCollector {
+	return &CgroupStatsCollector{
+		provider: provider,
+		prefix:   prefix,
+	}
+}
+
+// Describe does not send any descriptors, which makes this an unchecked collector. The set of metrics depends on the
+// cgroup version, as well as the block devices present on the host, so it is not known ahead of time.
+func (c *CgroupStatsCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+func (c *CgroupStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	collection, err := c.provider.GetCgroupStatsByPrefix(c.prefix)
+	if err != nil {
+		desc := prometheus.NewDesc(prometheus.BuildFQName(MetricNamespace, "", "collection_error"),
+			"Error collecting cgroup stats.", nil, nil)
+		ch <- prometheus.NewInvalidMetric(desc, err)
+		return
+	}
+
+	descs := map[string]*prometheus.Desc{}
+	for _, cgroupMetrics := range collection.ToMetricsOutput().Cgroups {
+		for _, metric := range cgroupMetrics.Metrics {
+			desc, ok := descs[metric.Name]
+			if !ok {
+				labelNames := append([]string{CgroupLabel}, metric.LabelNames...)
+				desc = prometheus.NewDesc(prometheus.BuildFQName(MetricNamespace, "", metric.Name), metric.Help,
+					labelNames, nil)
+				descs[metric.Name] = desc
+			}
+			labelValues := append([]string{cgroupMetrics.Name}, metric.LabelValues...)
+			ch <- prometheus.MustNewConstMetric(desc, toValueType(metric.Type), metric.Value, labelValues...)
+		}
+	}
+}
+
+func toValueType(metricType common.MetricType) prometheus.ValueType {
+	if metricType == common.CounterMetric {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
@@ -0,0 +1,79 @@
+package serve
+
+import (
+	"io"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider returns a fixed collection, and records the highest number of concurrent calls.
+type fakeProvider struct {
+	common.CgroupStatsProvider
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (f *fakeProvider) GetCgroupStatsByPrefix(_ string) (common.CgroupStatsCollection, error) {
+	inFlight := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	if inFlight > f.maxInFlight.Load() {
+		f.maxInFlight.Store(inFlight)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	return common.Collection[string]{
+		Stats: []string{"/a.service"},
+		MetricsTransformer: func(name string) *common.CgroupMetrics {
+			return &common.CgroupMetrics{
+				Name: name,
+				Metrics: []*common.Metric{
+					common.NewCounter("cpu_usage_seconds_total", "Total CPU time.", 1.5),
+					common.NewGauge("memory_usage_bytes", "Memory usage.", 1024).WithLabel("type", "anon"),
+				},
+			}
+		},
+	}, nil
+}
+
+func TestCgroupStatsCollector(t *testing.T) {
+	// Given
+	provider := &fakeProvider{}
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(NewCgroupStatsCollector(provider, "/")))
+	server := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	defer server.Close()
+
+	// When
+	bodies := make([]string, 2)
+	var wg sync.WaitGroup
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := server.Client().Get(server.URL)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			bodies[i] = string(body)
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	assert.Equal(t, int32(1), provider.maxInFlight.Load(), "Scrapes should not collect stats concurrently.")
+	for _, body := range bodies {
+		assert.Contains(t, body, "# TYPE cgstat_cpu_usage_seconds_total counter")
+		assert.Contains(t, body, `cgstat_cpu_usage_seconds_total{cgroup="/a.service"} 1.5`)
+		assert.Contains(t, body, "# TYPE cgstat_memory_usage_bytes gauge")
+		assert.Contains(t, body, `cgstat_memory_usage_bytes{cgroup="/a.service",type="anon"} 1024`)
+	}
+}
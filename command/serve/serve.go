@@ -0,0 +1,49 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/urfave/cli"
+)
+
+const (
+	MetricsPath = "/metrics"
+)
+
+func Register() cli.Command {
+	return cli.Command{
+		Name:   "serve",
+		Usage:  "Expose cgroup stats as Prometheus metrics over HTTP.",
+		Action: action,
+		Flags:  flags(),
+	}
+}
+
+func action(cCtx *cli.Context) error {
+	serveArgs, err := parseArgs(cCtx)
+	if err != nil {
+		return err
+	}
+
+	opts := []common.ProviderOpt{common.WithConcurrency(serveArgs.Concurrency)}
+	if serveArgs.HasDevice() {
+		opts = append(opts, common.WithDeviceFilter(serveArgs.Device))
+	}
+	provider := stats.NewCgroupStatsProvider(opts...)
+
+	registry := prometheus.NewRegistry()
+	if err = registry.Register(NewCgroupStatsCollector(provider, serveArgs.CgroupPrefix)); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	fmt.Printf("Serving metrics on %s%s\n", serveArgs.ListenAddress, MetricsPath)
+	return http.ListenAndServe(serveArgs.ListenAddress, mux)
+}
@@ -3,9 +3,13 @@ package view
 import (
 	"errors"
 	"fmt"
+	"github.com/strategicpause/cgstat/alert"
+	"github.com/strategicpause/cgstat/stats/common"
 	"github.com/urfave/cli"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +20,33 @@ const (
 	ArgOut             = "out"
 	ArgFollow          = "follow"
 	ArgRefreshInterval = "refresh-interval"
+	ArgDevice          = "device"
+	ArgFormat          = "format"
+	ArgInteractive     = "interactive"
+	ArgPlain           = "plain"
+	ArgSort            = "sort"
+	ArgTop             = "top"
+	ArgTree            = "tree"
+	ArgMaxDepth        = "max-depth"
+	ArgGroupBy         = "group-by"
+	ArgRollup          = "rollup"
+	ArgRecord          = "record"
+	ArgAlert           = "alert"
+	ArgAlertHook       = "alert-hook"
+	ArgExitOnAlert     = "exit-on-alert"
+	ArgLifecycle       = "lifecycle"
+	ArgSampleWindow    = "sample-window"
+	ArgStateFile       = "state-file"
+	ArgConcurrency     = "concurrency"
+)
+
+// ExitCodeAlert is the exit status of the view command when it exits because an alert fired.
+const ExitCodeAlert = 2
+
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
 )
 
 type Args struct {
@@ -25,6 +56,32 @@ type Args struct {
 	OutputFile      string
 	FollowMode      bool
 	RefreshInterval float64
+	Device          string
+	Format          string
+	Interactive     bool
+	// Plain keeps follow mode as a table which is redrawn every interval, rather than the interactive view.
+	Plain    bool
+	Sort     string
+	SortKeys []*common.SortKey
+	Top      int
+	Tree     bool
+	MaxDepth int
+	GroupBy  string
+	Rollup   bool
+	// RollupDepth is the depth of the ancestor which stats are summed into, or -1 if stats are not rolled up.
+	RollupDepth int
+	RecordFile  string
+	Alerts      []string
+	AlertRules  []*alert.Rule
+	AlertHook   string
+	ExitOnAlert bool
+	Lifecycle   bool
+	// SampleWindow is the time in seconds between the two samples taken without follow mode.
+	SampleWindow float64
+	StateFile    string
+	// Concurrency is the maximum number of cgroups whose stats are collected at the same time, where 0 uses the
+	// number of CPUs.
+	Concurrency int
 }
 
 func flags() []cli.Flag {
@@ -54,6 +111,89 @@ func flags() []cli.Flag {
 			Usage: "Refresh interval in seconds",
 			Value: 1.0,
 		},
+		cli.StringFlag{
+			Name:  "device",
+			Usage: "Only show IO stats for the given block device name (ex: nvme0n1p2) or major:minor number.",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format of the output printed to the screen: table, json or ndjson.",
+			Value: FormatTable,
+		},
+		cli.BoolFlag{
+			Name: "interactive",
+			Usage: "Starts an interactive, top-like view which refreshes every interval. This is the default for " +
+				"--follow in a terminal, unless it is combined with options the interactive view cannot show.",
+		},
+		cli.BoolFlag{
+			Name:  "plain",
+			Usage: "Redraws a plain table every interval in follow mode, rather than starting the interactive view.",
+		},
+		cli.StringFlag{
+			Name: "sort",
+			Usage: "Comma-separated list of fields to sort cgroups by, each optionally followed by :asc or :desc " +
+				"(ex: memory:desc,cpu). Fields: name, cpu, memory, pids, oom_kills, fds, sockets, io.",
+		},
+		cli.IntFlag{
+			Name:  "top",
+			Usage: "Only show the first N cgroups after sorting.",
+		},
+		cli.BoolFlag{
+			Name:  "tree",
+			Usage: "Display cgroups as a tree, with each cgroup indented under its parent.",
+		},
+		cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "Maximum depth of the tree to display, where 0 only shows the top-level cgroups. Unlimited if negative.",
+			Value: -1,
+		},
+		cli.StringFlag{
+			Name: "group-by",
+			Usage: "Sums the stats of each cgroup into its ancestor at the given depth, followed by a host total " +
+				"(ex: depth=2).",
+		},
+		cli.BoolFlag{
+			Name:  "rollup",
+			Usage: "Sums the stats of each cgroup into its ancestor directly below the prefix. Same as --group-by with the depth of the prefix plus one.",
+		},
+		cli.StringFlag{
+			Name:  "record",
+			Usage: "Records every sample of raw stats to the given file, which can be played back with the replay command.",
+		},
+		cli.StringSliceFlag{
+			Name: "alert",
+			Usage: "Rule which fires an alert on stderr, such as \"memory.usage_pct > 90 for 30s\" or " +
+				"\"oom_kill increased\". Can be repeated. Metrics: " + strings.Join(alert.MetricNames(), ", ") + ".",
+		},
+		cli.StringFlag{
+			Name: "alert-hook",
+			Usage: "Shell command which is run for each alert. The alert is described by the CGSTAT_ALERT_TIME, " +
+				"CGSTAT_ALERT_RULE, CGSTAT_ALERT_METRIC, CGSTAT_ALERT_CGROUP and CGSTAT_ALERT_VALUE environment variables.",
+		},
+		cli.BoolFlag{
+			Name:  "exit-on-alert",
+			Usage: fmt.Sprintf("Exits with status %d after the first sample which fires an alert.", ExitCodeAlert),
+		},
+		cli.BoolFlag{
+			Name:  "lifecycle",
+			Usage: "Prints cgroups which were created or removed between samples to stderr, with the final stats of removed cgroups.",
+		},
+		cli.Float64Flag{
+			Name: "sample-window",
+			Usage: "Without --follow, the time in seconds between the two samples from which CPU utilization is " +
+				"computed. 0 only takes a single sample, in which CPU utilization is always 0%.",
+			Value: 0.5,
+		},
+		cli.StringFlag{
+			Name: "state-file",
+			Usage: "Without --follow, uses the sample persisted in the given file by a previous run to compute CPU " +
+				"utilization, then persists the current sample for the next run. The sample window is only used if " +
+				"the file could not be read.",
+		},
+		cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "Maximum number of cgroups whose stats are collected at the same time. Defaults to the number of CPUs.",
+		},
 	}
 }
 
@@ -65,6 +205,51 @@ func parseArgs(cCtx *cli.Context) (*Args, error) {
 		OutputFile:      cCtx.String(ArgOut),
 		FollowMode:      cCtx.Bool(ArgFollow),
 		RefreshInterval: cCtx.Float64(ArgRefreshInterval),
+		Device:          cCtx.String(ArgDevice),
+		Format:          cCtx.String(ArgFormat),
+		Interactive:     cCtx.Bool(ArgInteractive),
+		Plain:           cCtx.Bool(ArgPlain),
+		Sort:            cCtx.String(ArgSort),
+		Top:             cCtx.Int(ArgTop),
+		Tree:            cCtx.Bool(ArgTree),
+		MaxDepth:        cCtx.Int(ArgMaxDepth),
+		GroupBy:         cCtx.String(ArgGroupBy),
+		Rollup:          cCtx.Bool(ArgRollup),
+		RollupDepth:     -1,
+		RecordFile:      cCtx.String(ArgRecord),
+		Alerts:          cCtx.StringSlice(ArgAlert),
+		AlertHook:       cCtx.String(ArgAlertHook),
+		ExitOnAlert:     cCtx.Bool(ArgExitOnAlert),
+		Lifecycle:       cCtx.Bool(ArgLifecycle),
+		SampleWindow:    cCtx.Float64(ArgSampleWindow),
+		StateFile:       cCtx.String(ArgStateFile),
+		Concurrency:     cCtx.Int(ArgConcurrency),
+	}
+
+	for _, expression := range viewArgs.Alerts {
+		rule, err := alert.ParseRule(expression)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing list args: %s", err)
+		}
+		viewArgs.AlertRules = append(viewArgs.AlertRules, rule)
+	}
+
+	if viewArgs.GroupBy != "" {
+		depth, err := parseGroupBy(viewArgs.GroupBy)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing list args: %s", err)
+		}
+		viewArgs.RollupDepth = depth
+	} else if viewArgs.Rollup {
+		viewArgs.RollupDepth = common.CgroupDepth(viewArgs.CgroupPrefix) + 1
+	}
+
+	if viewArgs.HasSort() {
+		sortKeys, err := common.ParseSortKeys(viewArgs.Sort)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing list args: %s", err)
+		}
+		viewArgs.SortKeys = sortKeys
 	}
 
 	if err := validateArguments(viewArgs); err != nil {
@@ -81,11 +266,63 @@ func validateArguments(args *Args) error {
 	if args.VerboseOutput && args.CgroupPrefix != "" {
 		return errors.New("you must specify a cgroup name when using verbose output")
 	}
+	if args.Format != FormatTable && args.Format != FormatJSON && args.Format != FormatNDJSON {
+		return fmt.Errorf("unknown format %q, must be one of: %s, %s, %s", args.Format, FormatTable, FormatJSON,
+			FormatNDJSON)
+	}
+	if args.VerboseOutput && args.Format != FormatTable {
+		return errors.New("verbose output is only supported by the table format")
+	}
+	if args.Interactive && (args.VerboseOutput || args.HasOutputFile() || args.Format != FormatTable) {
+		return errors.New("interactive mode cannot be combined with verbose, file or JSON output")
+	}
+	if args.Top < 0 {
+		return errors.New("you must specify a non-negative number of cgroups to show")
+	}
+	if args.Tree && args.HasTop() {
+		return errors.New("a tree of cgroups cannot be limited with --top, use --max-depth instead")
+	}
+	if args.Tree && args.Interactive {
+		return errors.New("interactive mode always displays cgroups as a tree")
+	}
+	if !args.Tree && args.MaxDepth >= 0 {
+		return errors.New("--max-depth can only be used with --tree")
+	}
+	if args.GroupBy != "" && args.Rollup {
+		return errors.New("--group-by and --rollup cannot be used together")
+	}
+	if args.IsRollup() && !args.HasPrefix() {
+		return errors.New("you must specify a cgroup prefix when rolling up stats")
+	}
+	if args.IsRollup() && (args.Tree || args.Interactive || args.HasTop() || args.VerboseOutput) {
+		return errors.New("rolled up stats cannot be combined with tree, interactive, top or verbose output")
+	}
+	if !args.HasAlerts() && (args.AlertHook != "" || args.ExitOnAlert) {
+		return errors.New("--alert-hook and --exit-on-alert require at least one --alert rule")
+	}
+	if (args.HasAlerts() || args.Lifecycle) && args.Interactive {
+		return errors.New("alerts and lifecycle events cannot be used in interactive mode")
+	}
+	if args.Plain && (!args.FollowMode || args.Interactive) {
+		return errors.New("--plain can only be used with --follow, and not with --interactive")
+	}
+	if args.SampleWindow < 0.0 {
+		return errors.New("you must specify a non-negative sample window")
+	}
+	if args.HasStateFile() && (args.FollowMode || args.Interactive) {
+		return errors.New("a state file can only be used without follow or interactive mode")
+	}
 	if args.RefreshInterval < 0.0 {
 		return errors.New("you must specify a non-negative refresh interval")
 	}
-	if args.HasOutputFile() {
-		base, err := filepath.Abs(args.OutputFile)
+	if args.Concurrency < 0 {
+		return errors.New("you must specify a non-negative concurrency")
+	}
+	for _, filename := range []string{args.OutputFile, args.RecordFile, args.StateFile} {
+		if filename == "" {
+			continue
+		}
+		base, err := filepath.Abs(filename)
 		if err != nil {
 			return err
 		}
@@ -97,10 +334,71 @@ func validateArguments(args *Args) error {
 	return nil
 }
 
+// parseGroupBy parses the depth from a group-by expression of the form depth=N.
+func parseGroupBy(groupBy string) (int, error) {
+	key, value, found := strings.Cut(groupBy, "=")
+	if !found || strings.TrimSpace(key) != "depth" {
+		return 0, fmt.Errorf("unknown group-by %q, must be of the form depth=N", groupBy)
+	}
+	depth, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid group-by depth %q, must be a non-negative integer", value)
+	}
+	return depth, nil
+}
+
+// UseInteractive returns true if the interactive view should be started, either because it was asked for, or because
+// a table is followed in a terminal. Follow mode keeps redrawing a plain table with --plain, or when combined with
+// output the interactive view cannot show, such as verbose, file, tree or rolled up output, or alerts and lifecycle
+// events written to stderr.
+func (a *Args) UseInteractive(isTerminal bool) bool {
+	if a.Interactive {
+		return true
+	}
+	return a.FollowMode && !a.Plain && isTerminal && a.IsTableFormat() && !a.VerboseOutput && !a.HasOutputFile() &&
+		!a.Tree && !a.IsRollup() && !a.HasAlerts() && !a.Lifecycle
+}
+
 func (a *Args) HasPrefix() bool {
 	return a.CgroupPrefix != ""
 }
 
+func (a *Args) HasSort() bool {
+	return a.Sort != ""
+}
+
+func (a *Args) IsRollup() bool {
+	return a.RollupDepth >= 0
+}
+
+func (a *Args) HasTop() bool {
+	return a.Top > 0
+}
+
+func (a *Args) IsTableFormat() bool {
+	return a.Format == FormatTable
+}
+
+func (a *Args) HasDevice() bool {
+	return a.Device != ""
+}
+
+func (a *Args) HasAlerts() bool {
+	return len(a.AlertRules) > 0
+}
+
+func (a *Args) HasStateFile() bool {
+	return a.StateFile != ""
+}
+
+func (a *Args) GetSampleWindow() time.Duration {
+	return time.Duration(a.SampleWindow * float64(time.Second))
+}
+
+func (a *Args) HasRecordFile() bool {
+	return a.RecordFile != ""
+}
+
 func (a *Args) HasOutputFile() bool {
 	return a.OutputFile != ""
 }
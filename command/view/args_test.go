@@ -0,0 +1,41 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgs_UseInteractive(t *testing.T) {
+	followArgs := func() *Args {
+		return &Args{FollowMode: true, Format: FormatTable, RollupDepth: -1}
+	}
+
+	t.Run("Follow a table in a terminal with the interactive view.", func(t *testing.T) {
+		assert.True(t, followArgs().UseInteractive(true))
+	})
+
+	t.Run("Redraw a plain table outside of a terminal.", func(t *testing.T) {
+		assert.False(t, followArgs().UseInteractive(false))
+	})
+
+	t.Run("Redraw a plain table when asked to.", func(t *testing.T) {
+		args := followArgs()
+		args.Plain = true
+		assert.False(t, args.UseInteractive(true))
+	})
+
+	t.Run("Redraw a plain table for output the interactive view cannot show.", func(t *testing.T) {
+		args := followArgs()
+		args.VerboseOutput = true
+		assert.False(t, args.UseInteractive(true))
+
+		args = followArgs()
+		args.Format = FormatNDJSON
+		assert.False(t, args.UseInteractive(true))
+	})
+
+	t.Run("Print a single sample without follow mode.", func(t *testing.T) {
+		assert.False(t, (&Args{Format: FormatTable, RollupDepth: -1}).UseInteractive(true))
+	})
+}
@@ -2,12 +2,17 @@ package view
 
 import (
 	"fmt"
+	"github.com/strategicpause/cgstat/alert"
+	"github.com/strategicpause/cgstat/record"
 	"github.com/strategicpause/cgstat/stats"
 	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/strategicpause/cgstat/tui"
 	"github.com/strategicpause/cgstat/writer"
+	"os"
 	"time"
 
 	"github.com/urfave/cli"
+	"golang.org/x/term"
 )
 
 // CgroupStatsProviderFn controls which set of CgroupStats are returned for a user request.
@@ -16,8 +21,15 @@ type CgroupStatsProviderFn func() (common.CgroupStatsCollection, error)
 type Command struct {
 	writers         []writer.StatsWriter
 	statsProviderFn CgroupStatsProviderFn
-	followMode      bool
-	ticker          *time.Ticker
+	// primeFn takes the first of the two samples in one-shot mode, which are separated by the sample window.
+	primeFn      CgroupStatsProviderFn
+	sampleWindow time.Duration
+	followMode   bool
+	clearScreen  bool
+	ticker       *time.Ticker
+	// alerts evaluates alert rules against each sample, and is nil if there are no rules.
+	alerts      *alert.AlertingCgroupStatsProvider
+	exitOnAlert bool
 }
 
 func Register() cli.Command {
@@ -38,15 +50,75 @@ func action(cCtx *cli.Context) error {
 		return err
 	}
 
+	provider := getProvider(viewArgs)
+	baseProvider := provider
+	sampleWindow := viewArgs.GetSampleWindow()
+	if viewArgs.HasStateFile() {
+		if loadState(provider, viewArgs.StateFile) {
+			sampleWindow = 0
+		}
+		// The state file is replaced by the current sample.
+		stateRecorder, err := record.NewRecorder(viewArgs.StateFile)
+		if err != nil {
+			return err
+		}
+		defer stateRecorder.Close()
+		provider = record.NewRecordingCgroupStatsProvider(provider, stateRecorder)
+	}
+	if viewArgs.HasRecordFile() {
+		recorder, err := record.NewRecorder(viewArgs.RecordFile)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		provider = record.NewRecordingCgroupStatsProvider(provider, recorder)
+	}
+	if viewArgs.Lifecycle {
+		provider = common.NewLifecycleTrackingCgroupStatsProvider(provider, func(event *common.LifecycleEvent) {
+			fmt.Fprintln(os.Stderr, event)
+		})
+	}
+	var alerts *alert.AlertingCgroupStatsProvider
+	if viewArgs.HasAlerts() {
+		alerts = alert.NewAlertingCgroupStatsProvider(provider, alert.NewEvaluator(viewArgs.AlertRules),
+			alert.NewNotifier(os.Stderr, viewArgs.AlertHook))
+		provider = alerts
+	}
+
+	isTerminal := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	if viewArgs.UseInteractive(isTerminal) {
+		app := tui.NewApp(tui.StatsProviderFn(getStatsProvider(viewArgs, provider)), baseProvider.GetCgroupStatsByName,
+			viewArgs.GetRefreshInterval(), getAppOpts(viewArgs)...)
+		return app.Run()
+	}
+
 	cmd := Command{
 		writers:         getWriters(viewArgs),
-		statsProviderFn: getStatsProvider(viewArgs),
+		statsProviderFn: getStatsProvider(viewArgs, provider),
+		primeFn:         getSampleProvider(viewArgs, baseProvider),
+		sampleWindow:    sampleWindow,
 		followMode:      viewArgs.FollowMode,
+		clearScreen:     viewArgs.IsTableFormat(),
 		ticker:          time.NewTicker(viewArgs.GetRefreshInterval()),
+		alerts:          alerts,
+		exitOnAlert:     viewArgs.ExitOnAlert,
 	}
 	return cmd.Run()
 }
 
+// getAppOpts starts the interactive view in the order given by --sort. With --top, rows are listed in that order
+// rather than as a tree, since the top cgroups are not necessarily siblings.
+func getAppOpts(args *Args) []tui.AppOpt {
+	var opts []tui.AppOpt
+	if args.HasSort() {
+		opts = append(opts, tui.WithSortKey(args.SortKeys[0]))
+	}
+	if args.HasTop() {
+		opts = append(opts, tui.WithFlatRows())
+	}
+	return opts
+}
+
 func getWriters(args *Args) []writer.StatsWriter {
 	var options []writer.ViewWriterOptions
 
@@ -54,18 +126,56 @@ func getWriters(args *Args) []writer.StatsWriter {
 		options = append(options, writer.WithCSVWriter(args.OutputFile))
 	}
 
-	displayVerbosity := writer.Normal
-	if args.VerboseOutput {
-		displayVerbosity = writer.Verbose
+	switch args.Format {
+	case FormatJSON:
+		options = append(options, writer.WithJSONWriter())
+	case FormatNDJSON:
+		options = append(options, writer.WithNDJSONWriter())
+	default:
+		displayVerbosity := writer.Normal
+		if args.VerboseOutput {
+			displayVerbosity = writer.Verbose
+		}
+		if args.Tree {
+			options = append(options, writer.WithTreeDisplayWriter())
+		} else {
+			options = append(options, writer.WithDisplayWriter(displayVerbosity))
+		}
 	}
-	options = append(options, writer.WithDisplayWriter(displayVerbosity))
 
 	return writer.NewViewWriters(options)
 }
 
-func getStatsProvider(args *Args) CgroupStatsProviderFn {
-	provider := stats.NewCgroupStatsProvider()
+func getProvider(args *Args) common.CgroupStatsProvider {
+	opts := []common.ProviderOpt{common.WithConcurrency(args.Concurrency)}
+	if args.HasDevice() {
+		opts = append(opts, common.WithDeviceFilter(args.Device))
+	}
+	return stats.NewCgroupStatsProvider(opts...)
+}
+
+// loadState uses the sample persisted in the given state file as the previous sample of the provider, and returns
+// false if the file does not contain a usable sample, such as on the first run.
+func loadState(provider common.CgroupStatsProvider, stateFile string) bool {
+	reader, err := record.Open(stateFile)
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+
+	sample, err := reader.Next()
+	if err != nil {
+		return false
+	}
+	collection, err := stats.DecodeCollection(sample.CgroupVersion, sample.Timestamp, sample.Cgroups)
+	if err != nil {
+		return false
+	}
+	return provider.SetPreviousSample(collection) == nil
+}
 
+// getSampleProvider returns the stats of the requested cgroups, without any sorting or aggregation.
+func getSampleProvider(args *Args, provider common.CgroupStatsProvider) CgroupStatsProviderFn {
 	if args.HasPrefix() {
 		return func() (common.CgroupStatsCollection, error) {
 			return provider.GetCgroupStatsByPrefix(args.CgroupPrefix)
@@ -76,17 +186,83 @@ func getStatsProvider(args *Args) CgroupStatsProviderFn {
 	}
 }
 
+func getStatsProvider(args *Args, provider common.CgroupStatsProvider) CgroupStatsProviderFn {
+	statsProviderFn := getSampleProvider(args, provider)
+	if args.IsRollup() {
+		sortKeys := args.SortKeys
+		if !args.HasSort() {
+			sortKeys = []*common.SortKey{{Field: common.NameField}}
+		}
+		return func() (common.CgroupStatsCollection, error) {
+			collection, err := statsProviderFn()
+			if err != nil {
+				return nil, err
+			}
+			return common.RollupCollection(collection, args.RollupDepth, sortKeys), nil
+		}
+	}
+	if args.Tree {
+		sortKeys := args.SortKeys
+		if !args.HasSort() {
+			sortKeys = []*common.SortKey{{Field: common.NameField}}
+		}
+		return func() (common.CgroupStatsCollection, error) {
+			collection, err := statsProviderFn()
+			if err != nil {
+				return nil, err
+			}
+			return common.TreeCollection(collection, sortKeys, args.MaxDepth), nil
+		}
+	}
+	if !args.HasSort() && !args.HasTop() {
+		return statsProviderFn
+	}
+
+	sortKeys := args.SortKeys
+	if !args.HasSort() {
+		// Without an explicit order, --top shows the cgroups using the most CPU.
+		sortKeys = []*common.SortKey{{Field: common.CPUField, Descending: true}}
+	}
+	return func() (common.CgroupStatsCollection, error) {
+		collection, err := statsProviderFn()
+		if err != nil {
+			return nil, err
+		}
+		return common.SortCollection(collection, sortKeys, args.Top), nil
+	}
+}
+
 func (c *Command) Run() error {
+	if !c.followMode {
+		if c.sampleWindow > 0 {
+			// Rates such as CPU utilization are computed from the previous sample of each cgroup.
+			if _, err := c.primeFn(); err != nil {
+				return err
+			}
+			time.Sleep(c.sampleWindow)
+		}
+		return c.writeSample()
+	}
 	for range c.ticker.C {
-		// Clear Screen
-		fmt.Print("\033[H\033[2J")
-		err := c.writeStats()
-		if err != nil {
+		if err := c.writeSample(); err != nil {
 			return err
 		}
-		if !c.followMode {
-			return nil
-		}
+	}
+	return nil
+}
+
+func (c *Command) writeSample() error {
+	if c.clearScreen {
+		// Clear Screen
+		fmt.Print("\033[H\033[2J")
+	}
+	err := c.writeStats()
+	if err != nil {
+		return err
+	}
+	if c.exitOnAlert && c.alerts.Fired() {
+		c.alerts.Wait()
+		return cli.NewExitError("exiting after an alert fired", ExitCodeAlert)
 	}
 	return nil
 }
@@ -0,0 +1,77 @@
+package watchtree
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ArgPrefix          = "prefix"
+	ArgRefreshInterval = "refresh-interval"
+	ArgFormat          = "format"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type Args struct {
+	CgroupPrefix    string
+	RefreshInterval float64
+	Format          string
+}
+
+func flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  ArgPrefix,
+			Usage: "Only watch cgroups with the given prefix.",
+			Value: "/",
+		},
+		cli.Float64Flag{
+			Name:  ArgRefreshInterval,
+			Usage: "Interval in seconds at which stats are sampled, to report the final stats of removed cgroups. 0 disables sampling.",
+			Value: 1.0,
+		},
+		cli.StringFlag{
+			Name:  ArgFormat,
+			Usage: "Format of each event: text, or json for one JSON document per line.",
+			Value: FormatText,
+		},
+	}
+}
+
+func parseArgs(cCtx *cli.Context) (*Args, error) {
+	watchTreeArgs := &Args{
+		CgroupPrefix:    cCtx.String(ArgPrefix),
+		RefreshInterval: cCtx.Float64(ArgRefreshInterval),
+		Format:          cCtx.String(ArgFormat),
+	}
+
+	if err := validateArguments(watchTreeArgs); err != nil {
+		return nil, fmt.Errorf("error parsing watch-tree args: %s", err)
+	}
+
+	return watchTreeArgs, nil
+}
+
+func validateArguments(args *Args) error {
+	if args.CgroupPrefix == "" {
+		return errors.New("cgroup prefix must be specified")
+	}
+	if args.RefreshInterval < 0.0 {
+		return errors.New("you must specify a non-negative refresh interval")
+	}
+	if args.Format != FormatText && args.Format != FormatJSON {
+		return fmt.Errorf("unknown format %q, must be one of: %s, %s", args.Format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+func (a *Args) GetRefreshInterval() time.Duration {
+	return time.Duration(a.RefreshInterval * float64(time.Second))
+}
@@ -0,0 +1,94 @@
+package watchtree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/urfave/cli"
+)
+
+func Register() cli.Command {
+	return cli.Command{
+		Name:  "watch-tree",
+		Usage: "Print cgroups as they are created or removed.",
+		Description: "Watches the cgroup hierarchy with inotify, so that even short-lived cgroups are noticed. Stats " +
+			"are sampled every interval, so that the final stats of a removed cgroup can be reported.",
+		Action: action,
+		Flags:  flags(),
+	}
+}
+
+func action(cCtx *cli.Context) error {
+	watchTreeArgs, err := parseArgs(cCtx)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := common.NewTreeWatcher(stats.CgroupRootDir(), watchTreeArgs.CgroupPrefix)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	tracker := common.NewLifecycleTracker()
+	// done is closed once the watcher stops, which stops sampling.
+	done := make(chan struct{})
+	defer close(done)
+	if watchTreeArgs.RefreshInterval > 0 {
+		go sample(stats.NewCgroupStatsProvider(), watchTreeArgs.CgroupPrefix, tracker,
+			watchTreeArgs.GetRefreshInterval(), done)
+	}
+
+	return watchTree(os.Stdout, watcher, tracker, watchTreeArgs.Format)
+}
+
+// watchTree writes each cgroup which is created or removed to the given writer in the given format, until the watcher
+// is closed or an event cannot be written.
+func watchTree(w io.Writer, watcher *common.TreeWatcher, tracker *common.LifecycleTracker, format string) error {
+	printEvent := func(event *common.LifecycleEvent) error {
+		_, err := fmt.Fprintln(w, event)
+		return err
+	}
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		printEvent = func(event *common.LifecycleEvent) error {
+			return encoder.Encode(event)
+		}
+	}
+
+	return watcher.Run(func(eventType common.LifecycleEventType, cgroup string) error {
+		var event *common.LifecycleEvent
+		if eventType == common.CgroupCreated {
+			event = tracker.Created(cgroup, time.Now())
+		} else {
+			event = tracker.Removed(cgroup, time.Now())
+		}
+		if err := printEvent(event); err != nil {
+			return fmt.Errorf("could not write event: %w", err)
+		}
+		return nil
+	})
+}
+
+// sample records the stats of every cgroup with the given prefix at the given interval, until done is closed.
+func sample(provider common.CgroupStatsProvider, prefix string, tracker *common.LifecycleTracker,
+	refreshInterval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		collection, err := provider.GetCgroupStatsByPrefix(prefix)
+		if err == nil {
+			tracker.Observe(collection.ToSnapshots())
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package watchtree
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchTree(t *testing.T) {
+	// Given
+	parentDir := t.TempDir()
+	rootDir := filepath.Join(parentDir, "cgroup")
+	assert.NoError(t, os.Mkdir(rootDir, 0755))
+	watcher, err := common.NewTreeWatcher(rootDir, "/")
+	assert.NoError(t, err)
+	defer watcher.Close()
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	go func() {
+		_ = watchTree(writer, watcher, common.NewLifecycleTracker(), FormatJSON)
+	}()
+	events := make(chan *common.LifecycleEvent, 10)
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			event := &common.LifecycleEvent{}
+			if json.Unmarshal(scanner.Bytes(), event) == nil {
+				events <- event
+			}
+		}
+	}()
+
+	// When
+	assert.NoError(t, os.Mkdir(filepath.Join(parentDir, "cgroup-other"), 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(rootDir, "a.slice"), 0755))
+	created := receive(t, events)
+	// A nested cgroup is only noticed on its own once its parent is watched, which is when its parent is reported.
+	assert.NoError(t, os.Mkdir(filepath.Join(rootDir, "a.slice", "b.scope"), 0755))
+	nestedCreated := receive(t, events)
+	assert.NoError(t, os.Remove(filepath.Join(rootDir, "a.slice", "b.scope")))
+	removed := receive(t, events)
+
+	// Then
+	assert.Equal(t, common.CgroupCreated, created.Type)
+	assert.Equal(t, "/a.slice", created.Cgroup, "Directories next to the cgroup root should not be watched.")
+	assert.Equal(t, common.CgroupCreated, nestedCreated.Type)
+	assert.Equal(t, "/a.slice/b.scope", nestedCreated.Cgroup)
+	assert.Equal(t, common.CgroupRemoved, removed.Type)
+	assert.Equal(t, "/a.slice/b.scope", removed.Cgroup)
+	select {
+	case event := <-events:
+		t.Fatalf("expected each change to be reported once, also received %s %s", event.Type, event.Cgroup)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func receive(t *testing.T, events <-chan *common.LifecycleEvent) *common.LifecycleEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event to be written")
+		return nil
+	}
+}
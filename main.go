@@ -4,8 +4,13 @@ import (
 	"log"
 	"os"
 
+	"github.com/strategicpause/cgstat/command/diff"
+	"github.com/strategicpause/cgstat/command/events"
 	"github.com/strategicpause/cgstat/command/list"
+	"github.com/strategicpause/cgstat/command/replay"
+	"github.com/strategicpause/cgstat/command/serve"
 	"github.com/strategicpause/cgstat/command/view"
+	"github.com/strategicpause/cgstat/command/watchtree"
 	"github.com/urfave/cli"
 )
 
@@ -23,5 +28,10 @@ func RegisterCommands() cli.Commands {
 	return cli.Commands{
 		list.Register(),
 		view.Register(),
+		serve.Register(),
+		replay.Register(),
+		diff.Register(),
+		events.Register(),
+		watchtree.Register(),
 	}
 }
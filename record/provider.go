@@ -0,0 +1,37 @@
+package record
+
+import (
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// RecordingCgroupStatsProvider is a CgroupStatsProvider which records every collection of stats returned by the
+// given provider, before they are sorted, filtered or aggregated.
+type RecordingCgroupStatsProvider struct {
+	common.CgroupStatsProvider
+	recorder *Recorder
+}
+
+func NewRecordingCgroupStatsProvider(provider common.CgroupStatsProvider, recorder *Recorder) common.CgroupStatsProvider {
+	return &RecordingCgroupStatsProvider{
+		CgroupStatsProvider: provider,
+		recorder:            recorder,
+	}
+}
+
+func (r *RecordingCgroupStatsProvider) GetCgroupStatsByPrefix(prefix string) (common.CgroupStatsCollection, error) {
+	return r.record(r.CgroupStatsProvider.GetCgroupStatsByPrefix(prefix))
+}
+
+func (r *RecordingCgroupStatsProvider) GetCgroupStatsByName(name string) (common.CgroupStatsCollection, error) {
+	return r.record(r.CgroupStatsProvider.GetCgroupStatsByName(name))
+}
+
+func (r *RecordingCgroupStatsProvider) record(collection common.CgroupStatsCollection, err error) (common.CgroupStatsCollection, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err = r.recorder.Write(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
@@ -0,0 +1,80 @@
+package record
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Reader reads the samples of a recording in the order in which they were recorded.
+type Reader struct {
+	file    *os.File
+	decoder *json.Decoder
+	// Header of the recording.
+	Header *Header
+}
+
+// Open opens the recording with the given filename and validates its header.
+func Open(filename string) (*Reader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open recording %s: %w", filename, err)
+	}
+
+	reader, err := newReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("could not read recording %s: %w", filename, err)
+	}
+	reader.file = file
+	return reader, nil
+}
+
+func newReader(r io.Reader) (*Reader, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(gzipReader)
+
+	header := &Header{}
+	if err = decoder.Decode(header); err != nil {
+		return nil, fmt.Errorf("could not decode header: %w", err)
+	}
+	if header.Format != Format {
+		return nil, errors.New("not a cgstat recording")
+	}
+	if header.Version < 1 || header.Version > Version {
+		return nil, fmt.Errorf("unsupported recording version %d, this version of cgstat supports up to %d",
+			header.Version, Version)
+	}
+
+	return &Reader{
+		decoder: decoder,
+		Header:  header,
+	}, nil
+}
+
+// Next returns the next sample of the recording, or io.EOF once all samples have been read. A recording which was
+// interrupted before it was closed ends with its last complete sample.
+func (r *Reader) Next() (*Sample, error) {
+	sample := &Sample{}
+	if err := r.decoder.Decode(sample); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("could not decode sample: %w", err)
+	}
+	return sample, nil
+}
+
+// Close closes the recording.
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,37 @@
+// Package record persists the raw samples of cgroup stats to a file, so that they can be replayed later.
+//
+// A recording is a gzip compressed stream of JSON documents, one per line. The first line is a Header, which
+// identifies the format and its version. Each following line is a common.JSONOutput with the stats of one sample.
+package record
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// Format identifies a file as a cgstat recording.
+	Format = "cgrec"
+	// Version is the version of the recording format written by this version of cgstat.
+	Version = 1
+)
+
+// Header is the first document of a recording.
+type Header struct {
+	Format  string
+	Version int
+	// Created is the time at which the recording was started.
+	Created time.Time
+	// Hostname of the host on which the stats were recorded.
+	Hostname string
+}
+
+// Sample is a recorded sample of stats for a set of cgroups, which is decoded lazily from its JSON encoded stats.
+type Sample struct {
+	// Timestamp is the time at which the stats were collected.
+	Timestamp time.Time
+	// CgroupVersion is either v1 or v2, which determines the schema of Cgroups.
+	CgroupVersion string
+	// Cgroups is the list of JSON encoded stats for each cgroup.
+	Cgroups json.RawMessage
+}
@@ -0,0 +1,69 @@
+package record
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats"
+	"github.com/strategicpause/cgstat/stats/common"
+	v2 "github.com/strategicpause/cgstat/stats/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecording(t *testing.T) {
+	// Given
+	collection := v2.NewCollection([]*v2.CgroupStats{
+		{
+			Name: "/system.slice/a.service",
+			CPU:  &v2.CPUStats{UsageInUsec: 1000, Utilization: 12.5},
+			PID:  &v2.PidStats{Current: 3, Limit: 10},
+		},
+	})
+
+	t.Run("Replay the samples of a closed recording.", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "session.cgrec")
+		recorder, err := NewRecorder(filename)
+		assert.NoError(t, err)
+		assert.NoError(t, recorder.Write(collection))
+		assert.NoError(t, recorder.Close())
+
+		samples := readSamples(t, filename)
+
+		assert.Len(t, samples, 1)
+		assert.Equal(t, common.CgroupV2, samples[0].CgroupVersion)
+		replayed, err := stats.DecodeCollection(samples[0].CgroupVersion, samples[0].Timestamp, samples[0].Cgroups)
+		assert.NoError(t, err)
+		assert.Equal(t, collection.ToSnapshots(), replayed.ToSnapshots())
+		assert.Equal(t, samples[0].Timestamp, replayed.ToJSONOutput().Timestamp)
+	})
+
+	t.Run("Replay the samples of an interrupted recording.", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "session.cgrec")
+		recorder, err := NewRecorder(filename)
+		assert.NoError(t, err)
+		assert.NoError(t, recorder.Write(collection))
+		assert.NoError(t, recorder.Write(collection))
+
+		samples := readSamples(t, filename)
+
+		assert.Len(t, samples, 2)
+	})
+}
+
+func readSamples(t *testing.T, filename string) []*Sample {
+	reader, err := Open(filename)
+	assert.NoError(t, err)
+	defer reader.Close()
+	assert.Equal(t, Version, reader.Header.Version)
+
+	var samples []*Sample
+	for {
+		sample, err := reader.Next()
+		if err == io.EOF {
+			return samples
+		}
+		assert.NoError(t, err)
+		samples = append(samples, sample)
+	}
+}
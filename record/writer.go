@@ -0,0 +1,77 @@
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// Recorder is an implementation of StatsWriter which will append each sample of cgroup stats to a recording.
+type Recorder struct {
+	file    *os.File
+	buffer  *bufio.Writer
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+}
+
+// NewRecorder creates a recording with the given filename, replacing any existing file.
+func NewRecorder(filename string) (*Recorder, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not create recording %s: %w", filename, err)
+	}
+
+	buffer := bufio.NewWriter(file)
+	gzipWriter := gzip.NewWriter(buffer)
+	recorder := &Recorder{
+		file:    file,
+		buffer:  buffer,
+		gzip:    gzipWriter,
+		encoder: json.NewEncoder(gzipWriter),
+	}
+
+	hostname, _ := os.Hostname()
+	header := &Header{
+		Format:   Format,
+		Version:  Version,
+		Created:  time.Now().UTC(),
+		Hostname: hostname,
+	}
+	if err = recorder.encode(header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return recorder, nil
+}
+
+func (r *Recorder) Write(collection common.CgroupStatsCollection) error {
+	return r.encode(collection.ToJSONOutput())
+}
+
+// encode writes the given document and flushes it to the file, so that a recording which is interrupted still
+// contains every sample up to that point.
+func (r *Recorder) encode(v interface{}) error {
+	if err := r.encoder.Encode(v); err != nil {
+		return fmt.Errorf("could not write recording: %w", err)
+	}
+	if err := r.gzip.Flush(); err != nil {
+		return fmt.Errorf("could not write recording: %w", err)
+	}
+	return r.buffer.Flush()
+}
+
+// Close completes the recording.
+func (r *Recorder) Close() error {
+	if err := r.gzip.Close(); err != nil {
+		return err
+	}
+	if err := r.buffer.Flush(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
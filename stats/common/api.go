@@ -1,6 +1,14 @@
 package common
 
-import "io"
+import (
+	"io"
+	"time"
+)
+
+const (
+	CgroupV1 = "v1"
+	CgroupV2 = "v2"
+)
 
 type CgroupStatsProvider interface {
 	// ListCgroupsByPrefix will return a list of cgroup names that start with the given prefix.
@@ -9,6 +17,9 @@ type CgroupStatsProvider interface {
 	GetCgroupStatsByPrefix(prefix string) (CgroupStatsCollection, error)
 	// GetCgroupStatsByName will return stats for the cgroup that matches the given name.
 	GetCgroupStatsByName(name string) (CgroupStatsCollection, error)
+	// SetPreviousSample will use the given collection, such as a sample persisted by a previous run, as the previous
+	// sample from which rates such as CPU utilization are computed.
+	SetPreviousSample(collection CgroupStatsCollection) error
 }
 
 type CgroupStatsCollection interface {
@@ -19,6 +30,15 @@ type CgroupStatsCollection interface {
 	// ToVerboseOutput will transform the write the given collection to the provided writer. There is no guarantee about
 	// the format of the data that is written to the given writer.
 	ToVerboseOutput(writer io.Writer)
+	// ToMetricsOutput will transform the underlying collection into a set of typed metrics which can be exported to a
+	// monitoring system.
+	ToMetricsOutput() *MetricsOutput
+	// ToJSONOutput will wrap the underlying collection, without any formatting, so that it can be encoded as JSON.
+	ToJSONOutput() *JSONOutput
+	// ToSnapshots will transform the underlying collection into a cgroup version independent model.
+	ToSnapshots() []*CgroupSnapshot
+	// Select will return a new collection with the cgroups at the given indices of ToSnapshots, in the given order.
+	Select(indices []int) CgroupStatsCollection
 }
 
 type CsvOutput struct {
@@ -30,3 +50,57 @@ type DisplayOutput struct {
 	Headers []interface{}
 	Rows    [][]interface{}
 }
+
+type JSONOutput struct {
+	// Timestamp is the time at which the stats were collected.
+	Timestamp time.Time
+	// CgroupVersion is either v1 or v2, which determines the schema of Cgroups. It is RollupSchema for stats which
+	// were summed by group.
+	CgroupVersion string
+	// Cgroups is the list of stats for each cgroup, either as v1.CgroupStats, v2.CgroupStats or Rollup.
+	Cgroups interface{}
+}
+
+type MetricType int
+
+const (
+	// CounterMetric is a cumulative value which only increases, unless it is reset.
+	CounterMetric MetricType = 0
+	// GaugeMetric is a value which can arbitrarily go up and down.
+	GaugeMetric MetricType = 1
+)
+
+type Metric struct {
+	// Name of the metric, such as cpu_usage_seconds_total.
+	Name string
+	Help string
+	Type MetricType
+	// LabelNames and LabelValues are additional dimensions of the metric, such as the block device.
+	LabelNames  []string
+	LabelValues []string
+	Value       float64
+}
+
+type CgroupMetrics struct {
+	Name    string
+	Metrics []*Metric
+}
+
+type MetricsOutput struct {
+	Cgroups []*CgroupMetrics
+}
+
+func NewCounter(name string, help string, value float64) *Metric {
+	return &Metric{Name: name, Help: help, Type: CounterMetric, Value: value}
+}
+
+func NewGauge(name string, help string, value float64) *Metric {
+	return &Metric{Name: name, Help: help, Type: GaugeMetric, Value: value}
+}
+
+// WithLabel adds a label to the metric and returns the metric.
+func (m *Metric) WithLabel(name string, value string) *Metric {
+	m.LabelNames = append(m.LabelNames, name)
+	m.LabelValues = append(m.LabelValues, value)
+	return m
+}
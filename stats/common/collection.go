@@ -1,8 +1,14 @@
 package common
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 type Collection[T any] struct {
+	CgroupVersion string
+	// Timestamp is the time at which the stats were collected. The current time is used if it is not set.
+	Timestamp          time.Time
 	Stats              []T
 	CsvHeadersProvider func() []string
 	CsvRowTransformer  func(T) []string
@@ -11,6 +17,10 @@ type Collection[T any] struct {
 	DisplayRowTransformer  func(T) []interface{}
 
 	VerboseOutputTransformer func(io.Writer, []T)
+
+	MetricsTransformer func(T) *CgroupMetrics
+
+	SnapshotTransformer func(T) *CgroupSnapshot
 }
 
 func (c Collection[T]) ToCsvOutput() *CsvOutput {
@@ -40,3 +50,49 @@ func (c Collection[T]) ToDisplayOutput() *DisplayOutput {
 func (c Collection[T]) ToVerboseOutput(w io.Writer) {
 	c.VerboseOutputTransformer(w, c.Stats)
 }
+
+func (c Collection[T]) ToMetricsOutput() *MetricsOutput {
+	metricsOutput := MetricsOutput{}
+
+	for _, s := range c.Stats {
+		metricsOutput.Cgroups = append(metricsOutput.Cgroups, c.MetricsTransformer(s))
+	}
+
+	return &metricsOutput
+}
+
+func (c Collection[T]) ToJSONOutput() *JSONOutput {
+	return &JSONOutput{
+		Timestamp:     c.timestamp(),
+		CgroupVersion: c.CgroupVersion,
+		Cgroups:       c.Stats,
+	}
+}
+
+func (c Collection[T]) timestamp() time.Time {
+	if c.Timestamp.IsZero() {
+		return time.Now().UTC()
+	}
+	return c.Timestamp
+}
+
+func (c Collection[T]) ToSnapshots() []*CgroupSnapshot {
+	snapshots := make([]*CgroupSnapshot, 0, len(c.Stats))
+
+	for _, s := range c.Stats {
+		snapshots = append(snapshots, c.SnapshotTransformer(s))
+	}
+
+	return snapshots
+}
+
+func (c Collection[T]) Select(indices []int) CgroupStatsCollection {
+	selected := c
+	selected.Stats = make([]T, 0, len(indices))
+
+	for _, i := range indices {
+		selected.Stats = append(selected.Stats, c.Stats[i])
+	}
+
+	return selected
+}
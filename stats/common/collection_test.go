@@ -91,3 +91,61 @@ func TestCollection_ToVerboseOutput(t *testing.T) {
 	// Then
 	assert.Equal(t, data, fakeWriter.GetWrittenData())
 }
+
+func TestCollection_ToMetricsOutput(t *testing.T) {
+	// Given
+	collection := Collection[string]{
+		Stats: []string{"a", "b"},
+		MetricsTransformer: func(s string) *CgroupMetrics {
+			return &CgroupMetrics{
+				Name:    s,
+				Metrics: []*Metric{NewGauge("metric", "help", 1.0).WithLabel("label", s)},
+			}
+		},
+	}
+
+	// When
+	metricsOutput := collection.ToMetricsOutput()
+
+	// Then
+	assert.Equal(t, []*CgroupMetrics{
+		{Name: "a", Metrics: []*Metric{
+			{Name: "metric", Help: "help", Type: GaugeMetric, LabelNames: []string{"label"}, LabelValues: []string{"a"}, Value: 1.0},
+		}},
+		{Name: "b", Metrics: []*Metric{
+			{Name: "metric", Help: "help", Type: GaugeMetric, LabelNames: []string{"label"}, LabelValues: []string{"b"}, Value: 1.0},
+		}},
+	}, metricsOutput.Cgroups)
+}
+
+func TestCollection_ToJSONOutput(t *testing.T) {
+	// Given
+	data := []string{"a", "b"}
+	collection := Collection[string]{
+		CgroupVersion: CgroupV2,
+		Stats:         data,
+	}
+
+	// When
+	jsonOutput := collection.ToJSONOutput()
+
+	// Then
+	assert.Equal(t, CgroupV2, jsonOutput.CgroupVersion)
+	assert.Equal(t, data, jsonOutput.Cgroups)
+}
+
+func TestCollection_ToSnapshots(t *testing.T) {
+	// Given
+	collection := Collection[string]{
+		Stats: []string{"a", "b"},
+		SnapshotTransformer: func(s string) *CgroupSnapshot {
+			return &CgroupSnapshot{Name: s}
+		},
+	}
+
+	// When
+	snapshots := collection.ToSnapshots()
+
+	// Then
+	assert.Equal(t, []*CgroupSnapshot{{Name: "a"}, {Name: "b"}}, snapshots)
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"fmt"
+)
+
+// SnapshotColumn describes how a field of a CgroupSnapshot is displayed. Rows are sorted using the raw value of the
+// field rather than its formatted text.
+type SnapshotColumn struct {
+	Header string
+	// Format returns the displayed value. The name column is displayed by the caller and has no Format.
+	Format func(*CgroupSnapshot) string
+	Field  *SnapshotField
+}
+
+const (
+	NameColumn = 0
+	CPUColumn  = 1
+	// NotAvailable is displayed when a stat is not reported for a cgroup.
+	NotAvailable = "-"
+	// HostNamespaceMarker is displayed next to socket counts which include every socket of the host, because the
+	// cgroup shares the network namespace of the host.
+	HostNamespaceMarker = " (host netns)"
+	// UnknownNamespaceMarker is displayed next to socket counts which include processes whose network namespace could
+	// not be read, which are counted as if they shared a single namespace.
+	UnknownNamespaceMarker = " (unknown netns)"
+)
+
+// SnapshotColumns are the columns used to display a CgroupSnapshot, regardless of the cgroup version.
+var SnapshotColumns = []*SnapshotColumn{
+	{
+		Header: "Name",
+		Field:  NameField,
+	},
+	{
+		Header: "CPU Cores / Limit",
+		Format: func(s *CgroupSnapshot) string {
+			if s.CPU == nil {
+				return NotAvailable
+			}
+			return FormatCPUCores(s.CPU.Utilization, s.CPU.Limit)
+		},
+		Field: CPUField,
+	},
+	{
+		Header: "Mem Usage",
+		Format: func(s *CgroupSnapshot) string {
+			if s.Memory == nil {
+				return NotAvailable
+			}
+			return DisplayRatio(s.Memory.Usage, s.Memory.Limit, WithBytes())
+		},
+		Field: MemoryField,
+	},
+	{
+		Header: "PIDs",
+		Format: func(s *CgroupSnapshot) string {
+			if s.PIDs == nil {
+				return NotAvailable
+			}
+			return fmt.Sprintf("%d", s.PIDs.Current)
+		},
+		Field: PIDsField,
+	},
+	{
+		Header: "OOM Kills",
+		Format: func(s *CgroupSnapshot) string {
+			if s.Events == nil {
+				return NotAvailable
+			}
+			return fmt.Sprintf("%d", s.Events.OomKills)
+		},
+		Field: OomKillsField,
+	},
+	{
+		Header: "Open Files",
+		Format: func(s *CgroupSnapshot) string {
+			if s.Proc == nil {
+				return NotAvailable
+			}
+			return FormatOpenFiles(s.Proc.NumFDs, s.Proc.FDLimitPercent, s.Proc.FDLimitPID)
+		},
+		Field: FDsField,
+	},
+	{
+		Header: "TCP / UDP Sockets",
+		Format: func(s *CgroupSnapshot) string {
+			if s.Network == nil {
+				return NotAvailable
+			}
+			sockets := fmt.Sprintf("%d / %d", s.Network.TCPSockets, s.Network.UDPSockets)
+			if s.Network.HostNamespace {
+				sockets += HostNamespaceMarker
+			}
+			if s.Network.UnknownNamespaceProcesses > 0 {
+				sockets += UnknownNamespaceMarker
+			}
+			return sockets
+		},
+		Field: SocketsField,
+	},
+	{
+		Header: "IO Read / Write",
+		Format: func(s *CgroupSnapshot) string {
+			if s.IO == nil {
+				return NotAvailable
+			}
+			total := s.IO.Total()
+			return fmt.Sprintf("%s / %s", FormatBytes(total.ReadBytes), FormatBytes(total.WriteBytes))
+		},
+		Field: IOField,
+	},
+}
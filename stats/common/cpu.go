@@ -0,0 +1,187 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CPULimit is the CPU bandwidth available to a cgroup, which is bounded both by the CFS quota of the cgroup and its
+// ancestors, and by the number of CPUs in its cpuset.
+type CPULimit struct {
+	// QuotaUsec is the CPU time the cgroup may use in each period, in microseconds, taken from the cgroup or ancestor
+	// with the tightest quota. Zero if there is no quota.
+	QuotaUsec uint64
+	// PeriodUsec is the length of the period of QuotaUsec, in microseconds.
+	PeriodUsec uint64
+	// NumCPUs is the number of CPUs on which the cgroup may run.
+	NumCPUs int
+	// Cores is the effective limit, as a number of cores, which is the lowest of the quota and the number of CPUs.
+	Cores float64
+}
+
+// CPUQuota is the CFS quota of a single cgroup.
+type CPUQuota struct {
+	// QuotaUsec is the CPU time the cgroup may use in each period, in microseconds. Zero if there is no quota.
+	QuotaUsec  uint64
+	PeriodUsec uint64
+}
+
+// Cores returns the quota as a number of cores, or +Inf if there is no quota.
+func (q CPUQuota) Cores() float64 {
+	if q.QuotaUsec == 0 || q.PeriodUsec == 0 {
+		return math.Inf(1)
+	}
+	return float64(q.QuotaUsec) / float64(q.PeriodUsec)
+}
+
+// NewCPULimit returns the effective CPU limit of a cgroup from the quotas of the cgroup and its ancestors, and the
+// number of CPUs in its cpuset. If the number of CPUs is unknown, the number of CPUs usable by cgstat is used instead.
+func NewCPULimit(quotas []CPUQuota, numCPUs int) *CPULimit {
+	if numCPUs <= 0 {
+		numCPUs = runtime.NumCPU()
+	}
+	limit := &CPULimit{
+		NumCPUs: numCPUs,
+		Cores:   float64(numCPUs),
+	}
+	// A quota which allows more cores than the cgroup can run on does not limit it.
+	for _, quota := range quotas {
+		if cores := quota.Cores(); cores < limit.Cores {
+			limit.QuotaUsec = quota.QuotaUsec
+			limit.PeriodUsec = quota.PeriodUsec
+			limit.Cores = cores
+		}
+	}
+	return limit
+}
+
+// GetCores returns the effective limit as a number of cores, or 0 if the limit is unknown.
+func (l *CPULimit) GetCores() float64 {
+	if l == nil {
+		return 0.0
+	}
+	return l.Cores
+}
+
+// UsagePercent returns the given CPU utilization, as a percentage of a single CPU, as a percentage of the limit.
+func (l *CPULimit) UsagePercent(utilization float64) float64 {
+	if l == nil || l.Cores == 0 {
+		return 0.0
+	}
+	return utilization / l.Cores
+}
+
+// String describes the limit, along with where it comes from.
+func (l *CPULimit) String() string {
+	if l == nil {
+		return NotAvailable
+	}
+	if l.QuotaUsec == 0 {
+		return fmt.Sprintf("%.2f cores (%d CPUs, no quota)", l.Cores, l.NumCPUs)
+	}
+	return fmt.Sprintf("%.2f cores (%dus quota per %dus period, %d CPUs)", l.Cores, l.QuotaUsec, l.PeriodUsec,
+		l.NumCPUs)
+}
+
+// FormatCPUCores displays the given CPU utilization, as a percentage of a single CPU, as the number of cores used out
+// of the limit, such as "1.52 / 4.00 (38.00%)".
+func FormatCPUCores(utilization float64, limit *CPULimit) string {
+	cores := utilization / 100.0
+	if limit == nil {
+		return fmt.Sprintf("%.2f", cores)
+	}
+	return fmt.Sprintf("%.2f / %.2f (%.2f%%)", cores, limit.Cores, limit.UsagePercent(utilization))
+}
+
+// ParseCPUList returns the number of CPUs in a CPU list such as "0-3,8,10-11", as found in cpuset.cpus.effective.
+func ParseCPUList(list string) (int, error) {
+	numCPUs := 0
+	for _, cpuRange := range strings.Split(strings.TrimSpace(list), ",") {
+		if cpuRange == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(cpuRange, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, fmt.Errorf("malformed CPU list %q", list)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return 0, fmt.Errorf("malformed CPU list %q", list)
+			}
+		}
+		numCPUs += end - start + 1
+	}
+	return numCPUs, nil
+}
+
+// ThrottlingRate is the throttling of a cgroup during the interval between two samples, so that a spike is visible
+// as soon as it happens, rather than being averaged over the lifetime of the cgroup.
+type ThrottlingRate struct {
+	// ThrottledPeriods is the number of periods in which the cgroup was throttled during the interval.
+	ThrottledPeriods uint64
+	// RunnablePeriods is the number of periods in which the cgroup was runnable during the interval.
+	RunnablePeriods uint64
+	// ThrottledPercent is the percentage of the runnable periods of the interval in which the cgroup was throttled.
+	ThrottledPercent float64
+	// ThrottledPeriodsPerSec is the number of throttled periods per second.
+	ThrottledPeriodsPerSec float64
+	// ThrottledUsecPerSec is the time the cgroup was throttled per second, in microseconds.
+	ThrottledUsecPerSec float64
+}
+
+// NewThrottlingRate returns the throttling of a cgroup from the increase of its throttling counters since the previous
+// sample. It returns nil if there was no previous sample, or if the counters were reset.
+func NewThrottlingRate(deltas *CounterDeltas) *ThrottlingRate {
+	throttledPeriods, ok := deltas.Delta(ThrottledPeriodsCounter)
+	if !ok {
+		return nil
+	}
+	runnablePeriods, ok := deltas.Delta(RunnablePeriodsCounter)
+	if !ok {
+		return nil
+	}
+	rate := &ThrottlingRate{
+		ThrottledPeriods:       throttledPeriods,
+		RunnablePeriods:        runnablePeriods,
+		ThrottledPeriodsPerSec: deltas.Rate(ThrottledPeriodsCounter),
+		ThrottledUsecPerSec:    deltas.Rate(ThrottledUsecCounter),
+	}
+	if runnablePeriods > 0 {
+		rate.ThrottledPercent = float64(throttledPeriods) / float64(runnablePeriods) * 100.0
+	}
+	return rate
+}
+
+// UtilizationFromDeltas returns the percentage of a single CPU used by a cgroup since the previous sample, or zero if
+// there was no previous sample or the usage was reset.
+func UtilizationFromDeltas(deltas *CounterDeltas) float64 {
+	return deltas.Rate(CPUUsageCounter) / usecPerSecond * 100.0
+}
+
+// ThrottlingRateCSVColumns returns the throttled percentage, throttled periods per second and throttled time per second
+// of the given rate, which are empty before a second sample is taken.
+func ThrottlingRateCSVColumns(rate *ThrottlingRate) []string {
+	if rate == nil {
+		return []string{"", "", ""}
+	}
+	return []string{
+		fmt.Sprintf("%f", rate.ThrottledPercent),
+		fmt.Sprintf("%f", rate.ThrottledPeriodsPerSec),
+		fmt.Sprintf("%f", rate.ThrottledUsecPerSec),
+	}
+}
+
+// FormatThrottlingRate displays the throttling of a cgroup during the last interval, such as
+// "12 / 50 (24.00%) 35.2ms/s", or NotAvailable before a second sample is taken.
+func FormatThrottlingRate(rate *ThrottlingRate) string {
+	if rate == nil {
+		return NotAvailable
+	}
+	return fmt.Sprintf("%s %.1fms/s", DisplayRatio(rate.ThrottledPeriods, rate.RunnablePeriods, WithTotal()),
+		rate.ThrottledUsecPerSec/1e3)
+}
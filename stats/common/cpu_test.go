@@ -0,0 +1,71 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPUList(t *testing.T) {
+	numCPUs, err := ParseCPUList("0-3,8,10-11\n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, numCPUs)
+
+	_, err = ParseCPUList("3-1")
+	assert.Error(t, err)
+}
+
+func TestNewCPULimit(t *testing.T) {
+	t.Run("Use the tightest quota of the cgroup and its ancestors.", func(t *testing.T) {
+		limit := NewCPULimit([]CPUQuota{{}, {QuotaUsec: 200000, PeriodUsec: 100000}, {QuotaUsec: 50000, PeriodUsec: 100000}}, 8)
+
+		assert.Equal(t, 0.5, limit.Cores)
+		assert.Equal(t, uint64(50000), limit.QuotaUsec)
+		assert.Equal(t, 50.0, limit.UsagePercent(25.0))
+		assert.Equal(t, "0.25 / 0.50 (50.00%)", FormatCPUCores(25.0, limit))
+	})
+
+	t.Run("Limit to the number of CPUs.", func(t *testing.T) {
+		limit := NewCPULimit([]CPUQuota{{QuotaUsec: 800000, PeriodUsec: 100000}}, 2)
+
+		assert.Equal(t, 2.0, limit.Cores)
+		assert.Equal(t, uint64(0), limit.QuotaUsec, "The quota does not limit the cgroup.")
+	})
+}
+
+func TestNewThrottlingRate(t *testing.T) {
+	t.Run("Compute throttling during the interval.", func(t *testing.T) {
+		// Given
+		deltas := &CounterDeltas{
+			ElapsedUsec: 2_000_000,
+			Deltas: map[string]uint64{
+				ThrottledPeriodsCounter: 5,
+				RunnablePeriodsCounter:  20,
+				ThrottledUsecCounter:    100_000,
+			},
+		}
+
+		// When
+		rate := NewThrottlingRate(deltas)
+
+		// Then
+		assert.Equal(t, &ThrottlingRate{
+			ThrottledPeriods:       5,
+			RunnablePeriods:        20,
+			ThrottledPercent:       25.0,
+			ThrottledPeriodsPerSec: 2.5,
+			ThrottledUsecPerSec:    50_000,
+		}, rate)
+		assert.Equal(t, "5 / 20 (25.00%) 50.0ms/s", FormatThrottlingRate(rate))
+	})
+
+	t.Run("Ignore counters of a recreated cgroup.", func(t *testing.T) {
+		// When
+		rate := NewThrottlingRate(nil)
+
+		// Then
+		assert.Nil(t, rate)
+		assert.Equal(t, NotAvailable, FormatThrottlingRate(rate))
+	})
+}
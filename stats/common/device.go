@@ -0,0 +1,127 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	SysDevBlockDir = "/sys/dev/block"
+	PartitionsFile = "/proc/partitions"
+)
+
+// DeviceResolver maps block device numbers (major:minor) to human-readable device names, such as nvme0n1p2.
+type DeviceResolver struct {
+	sysDevBlockDir string
+	partitionsFile string
+
+	mu         sync.Mutex
+	names      map[string]string
+	partitions map[string]string
+}
+
+func NewDeviceResolver() *DeviceResolver {
+	return newDeviceResolver(SysDevBlockDir, PartitionsFile)
+}
+
+func newDeviceResolver(sysDevBlockDir string, partitionsFile string) *DeviceResolver {
+	return &DeviceResolver{
+		sysDevBlockDir: sysDevBlockDir,
+		partitionsFile: partitionsFile,
+		names:          map[string]string{},
+	}
+}
+
+// Resolve returns the name of the block device with the given major and minor number. It first looks up the device
+// in /sys/dev/block, then falls back to /proc/partitions. If the device cannot be found, then major:minor is returned.
+func (d *DeviceResolver) Resolve(major uint64, minor uint64) string {
+	deviceNumber := FormatDeviceNumber(major, minor)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if name, ok := d.names[deviceNumber]; ok {
+		return name
+	}
+	name := d.resolve(deviceNumber)
+	d.names[deviceNumber] = name
+
+	return name
+}
+
+func (d *DeviceResolver) resolve(deviceNumber string) string {
+	// /sys/dev/block/<major:minor> is a symlink to the device directory, such as
+	// ../../devices/pci0000:00/0000:00:04.0/nvme/nvme0/nvme0n1/nvme0n1p2.
+	if target, err := os.Readlink(filepath.Join(d.sysDevBlockDir, deviceNumber)); err == nil {
+		return filepath.Base(target)
+	}
+	if d.partitions == nil {
+		d.partitions = readPartitions(d.partitionsFile)
+	}
+	if name, ok := d.partitions[deviceNumber]; ok {
+		return name
+	}
+	return deviceNumber
+}
+
+// readPartitions parses /proc/partitions, which has the following format:
+//
+//	major minor  #blocks  name
+//
+//	 259        0  500107608 nvme0n1
+//	 259        1     524288 nvme0n1p1
+func readPartitions(filename string) map[string]string {
+	partitions := map[string]string{}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return partitions
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		major, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			// Skip the header
+			continue
+		}
+		minor, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		partitions[FormatDeviceNumber(major, minor)] = fields[3]
+	}
+	return partitions
+}
+
+// FormatDeviceNumber returns the given major and minor device number in the major:minor format.
+func FormatDeviceNumber(major uint64, minor uint64) string {
+	return fmt.Sprintf("%d:%d", major, minor)
+}
+
+// ParseDeviceNumber parses a device number in the major:minor format.
+func ParseDeviceNumber(deviceNumber string) (uint64, uint64, error) {
+	majorStr, minorStr, found := strings.Cut(deviceNumber, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid device number %q", deviceNumber)
+	}
+	major, err := strconv.ParseUint(majorStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid device number %q: %w", deviceNumber, err)
+	}
+	minor, err := strconv.ParseUint(minorStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid device number %q: %w", deviceNumber, err)
+	}
+	return major, minor, nil
+}
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceResolver_Resolve(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	sysDevBlockDir := filepath.Join(dir, "block")
+	assert.NoError(t, os.Mkdir(sysDevBlockDir, 0755))
+	assert.NoError(t, os.Symlink("../../devices/pci0000:00/nvme/nvme0/nvme0n1/nvme0n1p2",
+		filepath.Join(sysDevBlockDir, "259:2")))
+	partitionsFile := filepath.Join(dir, "partitions")
+	partitions := "major minor  #blocks  name\n\n 259        0  500107608 nvme0n1\n   8        0   1048576 sda\n"
+	assert.NoError(t, os.WriteFile(partitionsFile, []byte(partitions), 0644))
+	resolver := newDeviceResolver(sysDevBlockDir, partitionsFile)
+
+	// When, Then
+	assert.Equal(t, "nvme0n1p2", resolver.Resolve(259, 2), "Resolve the device from /sys/dev/block.")
+	assert.Equal(t, "sda", resolver.Resolve(8, 0), "Fall back to /proc/partitions.")
+	assert.Equal(t, "7:1", resolver.Resolve(7, 1), "Return major:minor for unknown devices.")
+}
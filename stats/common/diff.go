@@ -0,0 +1,180 @@
+package common
+
+import (
+	"sort"
+)
+
+// DiffStatus describes how a cgroup changed between two samples.
+type DiffStatus string
+
+const (
+	DiffChanged   DiffStatus = "changed"
+	DiffUnchanged DiffStatus = "unchanged"
+	// DiffAdded is the status of a cgroup which only appears in the second sample.
+	DiffAdded DiffStatus = "added"
+	// DiffRemoved is the status of a cgroup which only appears in the first sample.
+	DiffRemoved DiffStatus = "removed"
+)
+
+// DiffField is a field which is compared between two samples.
+type DiffField struct {
+	*SnapshotField
+	// Counter is true if the field only increases, in which case its delta is the amount accumulated between the
+	// two samples, and a decrease means the counter was reset. Otherwise, the field is a gauge and its delta is the
+	// change of its value.
+	Counter bool
+	// Bytes is true if the field is an amount of bytes.
+	Bytes bool
+}
+
+var (
+	CPUUsageField = &SnapshotField{
+		Name: "cpu_usage_usec",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.CPU == nil {
+				return 0
+			}
+			return float64(s.CPU.UsageUsec)
+		},
+	}
+	ThrottledPeriodsField = &SnapshotField{
+		Name: "throttled_periods",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.CPU == nil {
+				return 0
+			}
+			return float64(s.CPU.ThrottledPeriods)
+		},
+	}
+	PageFaultsField = &SnapshotField{
+		Name: "page_faults",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Memory == nil {
+				return 0
+			}
+			return float64(s.Memory.PageFaults)
+		},
+	}
+	MajorPageFaultsField = &SnapshotField{
+		Name: "major_page_faults",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Memory == nil {
+				return 0
+			}
+			return float64(s.Memory.MajorPageFaults)
+		},
+	}
+	IOReadBytesField = &SnapshotField{
+		Name: "io_read_bytes",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.IO == nil {
+				return 0
+			}
+			return float64(s.IO.Total().ReadBytes)
+		},
+	}
+	IOWriteBytesField = &SnapshotField{
+		Name: "io_write_bytes",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.IO == nil {
+				return 0
+			}
+			return float64(s.IO.Total().WriteBytes)
+		},
+	}
+)
+
+// DiffFields contains the fields compared by DiffSnapshots, in the order in which they are reported.
+var DiffFields = []*DiffField{
+	{SnapshotField: CPUUsageField, Counter: true},
+	{SnapshotField: ThrottledPeriodsField, Counter: true},
+	{SnapshotField: PageFaultsField, Counter: true},
+	{SnapshotField: MajorPageFaultsField, Counter: true},
+	{SnapshotField: OomKillsField, Counter: true},
+	{SnapshotField: IOReadBytesField, Counter: true, Bytes: true},
+	{SnapshotField: IOWriteBytesField, Counter: true, Bytes: true},
+	{SnapshotField: MemoryField, Bytes: true},
+	{SnapshotField: PIDsField},
+	{SnapshotField: FDsField},
+	{SnapshotField: SocketsField},
+}
+
+// FieldDelta is the change of a single field of a cgroup between two samples.
+type FieldDelta struct {
+	Field  string
+	Before float64
+	After  float64
+	Delta  float64
+	// Reset is true if a counter decreased, such as when the cgroup was recreated between the two samples, in which
+	// case Delta is the amount accumulated since the reset rather than a negative change.
+	Reset bool `json:",omitempty"`
+}
+
+// CgroupDiff is the change of the stats of a single cgroup between two samples.
+type CgroupDiff struct {
+	Name   string
+	Status DiffStatus
+	// Deltas contains the change of each of the DiffFields, in the same order. A cgroup which was added or removed is
+	// compared against zero values.
+	Deltas []*FieldDelta
+}
+
+// DiffSnapshots compares the stats of each cgroup in the two given samples, ordered by the name of the cgroup.
+func DiffSnapshots(before []*CgroupSnapshot, after []*CgroupSnapshot) []*CgroupDiff {
+	beforeByName := make(map[string]*CgroupSnapshot, len(before))
+	for _, snapshot := range before {
+		beforeByName[snapshot.Name] = snapshot
+	}
+	afterByName := make(map[string]*CgroupSnapshot, len(after))
+	for _, snapshot := range after {
+		afterByName[snapshot.Name] = snapshot
+	}
+
+	var diffs []*CgroupDiff
+	for name, beforeSnapshot := range beforeByName {
+		afterSnapshot, ok := afterByName[name]
+		if !ok {
+			diffs = append(diffs, diffSnapshot(DiffRemoved, beforeSnapshot, &CgroupSnapshot{Name: name}))
+			continue
+		}
+		diffs = append(diffs, diffSnapshot(DiffChanged, beforeSnapshot, afterSnapshot))
+	}
+	for name, afterSnapshot := range afterByName {
+		if _, ok := beforeByName[name]; !ok {
+			diffs = append(diffs, diffSnapshot(DiffAdded, &CgroupSnapshot{Name: name}, afterSnapshot))
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
+
+func diffSnapshot(status DiffStatus, before *CgroupSnapshot, after *CgroupSnapshot) *CgroupDiff {
+	diff := &CgroupDiff{
+		Name:   after.Name,
+		Status: status,
+	}
+
+	changed := false
+	for _, field := range DiffFields {
+		delta := &FieldDelta{
+			Field:  field.Name,
+			Before: field.Value(before),
+			After:  field.Value(after),
+		}
+		delta.Delta = delta.After - delta.Before
+		if field.Counter && delta.Delta < 0 {
+			delta.Delta = delta.After
+			delta.Reset = true
+		}
+		changed = changed || delta.Delta != 0
+		diff.Deltas = append(diff.Deltas, delta)
+	}
+
+	if status == DiffChanged && !changed {
+		diff.Status = DiffUnchanged
+	}
+	return diff
+}
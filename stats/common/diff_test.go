@@ -0,0 +1,63 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	// Given
+	before := []*CgroupSnapshot{
+		{Name: "/b.service", Memory: &MemorySnapshot{Usage: 100, PageFaults: 10}},
+		{Name: "/a.service", PIDs: &PIDSnapshot{Current: 2}},
+		{Name: "/removed.service", PIDs: &PIDSnapshot{Current: 1}},
+	}
+	after := []*CgroupSnapshot{
+		{Name: "/added.service", PIDs: &PIDSnapshot{Current: 4}},
+		{Name: "/a.service", PIDs: &PIDSnapshot{Current: 2}},
+		{Name: "/b.service", Memory: &MemorySnapshot{Usage: 60, PageFaults: 15}},
+	}
+
+	// When
+	diffs := DiffSnapshots(before, after)
+
+	// Then
+	assert.Len(t, diffs, 4)
+	assert.Equal(t, "/a.service", diffs[0].Name)
+	assert.Equal(t, DiffUnchanged, diffs[0].Status)
+	assert.Equal(t, DiffAdded, diffs[1].Status)
+	assert.Equal(t, DiffChanged, diffs[2].Status)
+	assert.Equal(t, DiffRemoved, diffs[3].Status)
+	assert.Equal(t, "/removed.service", diffs[3].Name)
+
+	deltas := map[string]float64{}
+	for _, delta := range diffs[2].Deltas {
+		deltas[delta.Field] = delta.Delta
+	}
+	assert.Equal(t, 5.0, deltas[PageFaultsField.Name])
+	assert.Equal(t, -40.0, deltas[MemoryField.Name])
+}
+
+func TestDiffSnapshots_CounterReset(t *testing.T) {
+	// Given
+	before := []*CgroupSnapshot{
+		{Name: "/a.service", CPU: &CPUSnapshot{UsageUsec: 5_000_000}, Memory: &MemorySnapshot{Usage: 100}},
+	}
+	after := []*CgroupSnapshot{
+		{Name: "/a.service", CPU: &CPUSnapshot{UsageUsec: 1_000_000}, Memory: &MemorySnapshot{Usage: 60}},
+	}
+
+	// When
+	diffs := DiffSnapshots(before, after)
+
+	// Then
+	deltas := map[string]*FieldDelta{}
+	for _, delta := range diffs[0].Deltas {
+		deltas[delta.Field] = delta
+	}
+	assert.True(t, deltas[CPUUsageField.Name].Reset, "A counter which decreased should be reported as a reset.")
+	assert.Equal(t, 1_000_000.0, deltas[CPUUsageField.Name].Delta)
+	assert.False(t, deltas[MemoryField.Name].Reset, "A gauge which decreased is not a reset.")
+	assert.Equal(t, -40.0, deltas[MemoryField.Name].Delta)
+}
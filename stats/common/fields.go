@@ -0,0 +1,110 @@
+package common
+
+import (
+	"strings"
+)
+
+// SnapshotField is a stat of a CgroupSnapshot which can be used to sort or filter cgroups by its raw value.
+type SnapshotField struct {
+	Name string
+	// Value returns the raw value of the field. Fields which are not reported for a cgroup have a value of zero.
+	// The name field has no Value, and is compared alphabetically instead.
+	Value func(*CgroupSnapshot) float64
+}
+
+// Compare returns a negative number if a is less than b, a positive number if a is greater than b, and zero otherwise.
+func (f *SnapshotField) Compare(a *CgroupSnapshot, b *CgroupSnapshot) int {
+	if f.Value == nil {
+		return strings.Compare(a.Name, b.Name)
+	}
+	aValue, bValue := f.Value(a), f.Value(b)
+	switch {
+	case aValue < bValue:
+		return -1
+	case aValue > bValue:
+		return 1
+	}
+	return 0
+}
+
+var (
+	NameField = &SnapshotField{
+		Name: "name",
+	}
+	CPUField = &SnapshotField{
+		Name: "cpu",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.CPU == nil {
+				return 0
+			}
+			return s.CPU.Utilization
+		},
+	}
+	MemoryField = &SnapshotField{
+		Name: "memory",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Memory == nil {
+				return 0
+			}
+			return float64(s.Memory.Usage)
+		},
+	}
+	PIDsField = &SnapshotField{
+		Name: "pids",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.PIDs == nil {
+				return 0
+			}
+			return float64(s.PIDs.Current)
+		},
+	}
+	OomKillsField = &SnapshotField{
+		Name: "oom_kills",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Events == nil {
+				return 0
+			}
+			return float64(s.Events.OomKills)
+		},
+	}
+	FDsField = &SnapshotField{
+		Name: "fds",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Proc == nil {
+				return 0
+			}
+			return float64(s.Proc.NumFDs)
+		},
+	}
+	SocketsField = &SnapshotField{
+		Name: "sockets",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.Network == nil {
+				return 0
+			}
+			return float64(s.Network.TCPSockets + s.Network.UDPSockets)
+		},
+	}
+	IOField = &SnapshotField{
+		Name: "io",
+		Value: func(s *CgroupSnapshot) float64 {
+			if s.IO == nil {
+				return 0
+			}
+			total := s.IO.Total()
+			return float64(total.ReadBytes + total.WriteBytes)
+		},
+	}
+)
+
+// SnapshotFields contains every field which can be used to sort cgroups, keyed by name.
+var SnapshotFields = map[string]*SnapshotField{
+	NameField.Name:     NameField,
+	CPUField.Name:      CPUField,
+	MemoryField.Name:   MemoryField,
+	PIDsField.Name:     PIDsField,
+	OomKillsField.Name: OomKillsField,
+	FDsField.Name:      FDsField,
+	SocketsField.Name:  SocketsField,
+	IOField.Name:       IOField,
+}
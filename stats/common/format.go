@@ -21,6 +21,15 @@ func FormatBytes(bytes uint64) string {
 		float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// FormatOpenFiles displays the number of open file descriptors, followed by the usage of the process closest to its
+// file descriptor limit if known, such as "1200 (95.00% pid 42)".
+func FormatOpenFiles(numFDs uint64, limitPercent float64, limitPID int) string {
+	if limitPID == 0 {
+		return fmt.Sprintf("%d", numFDs)
+	}
+	return fmt.Sprintf("%d (%.2f%% pid %d)", numFDs, limitPercent, limitPID)
+}
+
 // defaultFormat is used when a formatted is not provided. This will simply turn the given uint64 into a string.
 func defaultFormat(n uint64) string {
 	return fmt.Sprintf("%d", n)
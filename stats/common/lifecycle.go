@@ -0,0 +1,193 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LifecycleEventType string
+
+const (
+	CgroupCreated LifecycleEventType = "created"
+	CgroupRemoved LifecycleEventType = "removed"
+)
+
+// LifecycleEvent reports that a cgroup was created or removed.
+type LifecycleEvent struct {
+	// Timestamp is the time at which the change was noticed.
+	Timestamp time.Time
+	Type      LifecycleEventType
+	Cgroup    string
+	// Snapshot contains the first stats of a created cgroup, or the last stats of a removed cgroup. It is nil if the
+	// cgroup was never sampled.
+	Snapshot *CgroupSnapshot
+	// Lifetime is how long a removed cgroup existed. It is zero if its creation was not noticed.
+	Lifetime time.Duration
+}
+
+// LifecycleTracker tracks the set of cgroups between samples, along with the last stats of each cgroup, so that the
+// final stats of a cgroup can be reported once it is removed.
+type LifecycleTracker struct {
+	mu sync.Mutex
+	// initialized is true once the first sample was seen. The cgroups of the first sample are not reported as
+	// created, since they already existed.
+	initialized bool
+	// snapshots contains the last stats of every known cgroup, or nil if a cgroup was not sampled yet.
+	snapshots map[string]*CgroupSnapshot
+	createdAt map[string]time.Time
+}
+
+func NewLifecycleTracker() *LifecycleTracker {
+	return &LifecycleTracker{
+		snapshots: map[string]*CgroupSnapshot{},
+		createdAt: map[string]time.Time{},
+	}
+}
+
+// Update compares the given sample to the previous one, and returns an event for each cgroup which appeared or
+// vanished, ordered by name.
+func (t *LifecycleTracker) Update(snapshots []*CgroupSnapshot, timestamp time.Time) []*LifecycleEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []*LifecycleEvent
+	current := make(map[string]*CgroupSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		current[snapshot.Name] = snapshot
+		if _, ok := t.snapshots[snapshot.Name]; !ok && t.initialized {
+			t.createdAt[snapshot.Name] = timestamp
+			events = append(events, &LifecycleEvent{
+				Timestamp: timestamp,
+				Type:      CgroupCreated,
+				Cgroup:    snapshot.Name,
+				Snapshot:  snapshot,
+			})
+		}
+	}
+	for name := range t.snapshots {
+		if _, ok := current[name]; !ok {
+			events = append(events, t.removed(name, timestamp))
+		}
+	}
+	t.snapshots = current
+	t.initialized = true
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Cgroup < events[j].Cgroup
+	})
+	return events
+}
+
+// Observe records the last stats of the cgroups which are already known, without reporting any changes. It is used
+// when creations and removals are noticed by other means, such as inotify.
+func (t *LifecycleTracker) Observe(snapshots []*CgroupSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, snapshot := range snapshots {
+		if _, ok := t.snapshots[snapshot.Name]; ok || !t.initialized {
+			t.snapshots[snapshot.Name] = snapshot
+		}
+	}
+	t.initialized = true
+}
+
+// Created records that the given cgroup was created, and returns the corresponding event.
+func (t *LifecycleTracker) Created(name string, timestamp time.Time) *LifecycleEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.snapshots[name] = nil
+	t.createdAt[name] = timestamp
+	return &LifecycleEvent{
+		Timestamp: timestamp,
+		Type:      CgroupCreated,
+		Cgroup:    name,
+	}
+}
+
+// Removed records that the given cgroup was removed, and returns the corresponding event with its last stats.
+func (t *LifecycleTracker) Removed(name string, timestamp time.Time) *LifecycleEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.removed(name, timestamp)
+}
+
+func (t *LifecycleTracker) removed(name string, timestamp time.Time) *LifecycleEvent {
+	event := &LifecycleEvent{
+		Timestamp: timestamp,
+		Type:      CgroupRemoved,
+		Cgroup:    name,
+		Snapshot:  t.snapshots[name],
+	}
+	if createdAt, ok := t.createdAt[name]; ok {
+		event.Lifetime = timestamp.Sub(createdAt)
+	}
+	delete(t.snapshots, name)
+	delete(t.createdAt, name)
+	return event
+}
+
+// String describes the event on a single line, including the last stats of a removed cgroup.
+func (e *LifecycleEvent) String() string {
+	line := fmt.Sprintf("%s %s %s", e.Timestamp.UTC().Format(time.RFC3339Nano), e.Type, e.Cgroup)
+	if e.Type != CgroupRemoved {
+		return line
+	}
+
+	var details []string
+	if e.Lifetime > 0 {
+		details = append(details, fmt.Sprintf("lifetime %s", e.Lifetime.Round(time.Millisecond)))
+	}
+	if s := e.Snapshot; s != nil {
+		details = append(details,
+			fmt.Sprintf("cpu %.2fs", CPUUsageField.Value(s)/1e6),
+			fmt.Sprintf("memory %s", FormatBytes(uint64(MemoryField.Value(s)))),
+			fmt.Sprintf("pids %.0f", PIDsField.Value(s)),
+			fmt.Sprintf("oom_kills %.0f", OomKillsField.Value(s)),
+		)
+	}
+	if len(details) == 0 {
+		return line
+	}
+	return fmt.Sprintf("%s (%s)", line, strings.Join(details, ", "))
+}
+
+// LifecycleTrackingCgroupStatsProvider is a CgroupStatsProvider which reports the cgroups which appeared or vanished
+// between two collections of stats returned by the given provider.
+type LifecycleTrackingCgroupStatsProvider struct {
+	CgroupStatsProvider
+	tracker *LifecycleTracker
+	handler func(*LifecycleEvent)
+}
+
+func NewLifecycleTrackingCgroupStatsProvider(provider CgroupStatsProvider,
+	handler func(*LifecycleEvent)) CgroupStatsProvider {
+	return &LifecycleTrackingCgroupStatsProvider{
+		CgroupStatsProvider: provider,
+		tracker:             NewLifecycleTracker(),
+		handler:             handler,
+	}
+}
+
+func (l *LifecycleTrackingCgroupStatsProvider) GetCgroupStatsByPrefix(prefix string) (CgroupStatsCollection, error) {
+	return l.track(l.CgroupStatsProvider.GetCgroupStatsByPrefix(prefix))
+}
+
+func (l *LifecycleTrackingCgroupStatsProvider) GetCgroupStatsByName(name string) (CgroupStatsCollection, error) {
+	return l.track(l.CgroupStatsProvider.GetCgroupStatsByName(name))
+}
+
+func (l *LifecycleTrackingCgroupStatsProvider) track(collection CgroupStatsCollection, err error) (CgroupStatsCollection, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range l.tracker.Update(collection.ToSnapshots(), time.Now()) {
+		l.handler(event)
+	}
+	return collection, nil
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleTracker(t *testing.T) {
+	// Given
+	tracker := NewLifecycleTracker()
+	start := time.Now()
+	a := &CgroupSnapshot{Name: "/a.service", PIDs: &PIDSnapshot{Current: 1}}
+	b := &CgroupSnapshot{Name: "/b.service"}
+
+	// When
+	initial := tracker.Update([]*CgroupSnapshot{a}, start)
+	created := tracker.Update([]*CgroupSnapshot{a, b}, start.Add(time.Second))
+	removed := tracker.Update([]*CgroupSnapshot{b}, start.Add(3*time.Second))
+
+	// Then
+	assert.Empty(t, initial, "Cgroups of the first sample already existed.")
+	assert.Len(t, created, 1)
+	assert.Equal(t, CgroupCreated, created[0].Type)
+	assert.Equal(t, "/b.service", created[0].Cgroup)
+	assert.Len(t, removed, 1)
+	assert.Equal(t, CgroupRemoved, removed[0].Type)
+	assert.Equal(t, a, removed[0].Snapshot, "The last stats of a removed cgroup are reported.")
+	assert.Equal(t, time.Duration(0), removed[0].Lifetime)
+}
+
+func TestTreeWatcher(t *testing.T) {
+	// Given
+	rootDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "system.slice"), 0755))
+	watcher, err := NewTreeWatcher(rootDir, "/system.slice")
+	assert.NoError(t, err)
+	defer watcher.Close()
+
+	type event struct {
+		eventType LifecycleEventType
+		cgroup    string
+	}
+	events := make(chan event, 10)
+	go func() {
+		_ = watcher.Run(func(eventType LifecycleEventType, cgroup string) error {
+			events <- event{eventType, cgroup}
+			return nil
+		})
+	}()
+
+	// When
+	assert.NoError(t, os.Mkdir(filepath.Join(rootDir, "system.slice", "job.scope"), 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(rootDir, "user.slice"), 0755))
+	assert.NoError(t, os.Remove(filepath.Join(rootDir, "system.slice", "job.scope")))
+
+	// Then
+	var received []event
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case e := <-events:
+			received = append(received, e)
+		case <-timeout:
+			t.Fatalf("expected 2 events, received %d", len(received))
+		}
+	}
+	assert.Equal(t, []event{
+		{CgroupCreated, "/system.slice/job.scope"},
+		{CgroupRemoved, "/system.slice/job.scope"},
+	}, received)
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"runtime"
+	"strings"
+)
+
+type ProviderOpt func(*ProviderConfig)
+
+// ProviderConfig contains the settings which are shared by the cgroup v1 and v2 CgroupStatsProvider.
+type ProviderConfig struct {
+	// DeviceFilter limits IO stats to the block device with the given name or major:minor number. IO stats for all
+	// devices are returned when empty.
+	DeviceFilter string
+	// Concurrency is the maximum number of cgroups whose stats are collected at the same time.
+	Concurrency int
+}
+
+func NewProviderConfig(opts ...ProviderOpt) *ProviderConfig {
+	config := &ProviderConfig{
+		Concurrency: runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+func WithDeviceFilter(device string) ProviderOpt {
+	return func(p *ProviderConfig) {
+		p.DeviceFilter = strings.TrimPrefix(device, "/dev/")
+	}
+}
+
+// WithConcurrency sets the maximum number of cgroups whose stats are collected at the same time. The number of CPUs is
+// used if it is not positive.
+func WithConcurrency(concurrency int) ProviderOpt {
+	return func(p *ProviderConfig) {
+		if concurrency > 0 {
+			p.Concurrency = concurrency
+		}
+	}
+}
+
+// IncludesDevice returns true if IO stats for the given block device should be returned.
+func (p *ProviderConfig) IncludesDevice(major uint64, minor uint64, name string) bool {
+	if p.DeviceFilter == "" {
+		return true
+	}
+	return p.DeviceFilter == name || p.DeviceFilter == FormatDeviceNumber(major, minor)
+}
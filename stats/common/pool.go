@@ -0,0 +1,35 @@
+package common
+
+import "sync"
+
+// CollectConcurrently calls collect for each of the given cgroup paths, with at most concurrency calls running at the
+// same time, and returns the results and errors in the same order as the paths.
+func CollectConcurrently[T any](cgroupPaths []string, concurrency int, collect func(string) (T, error)) ([]T, []error) {
+	results := make([]T, len(cgroupPaths))
+	errs := make([]error, len(cgroupPaths))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(cgroupPaths) {
+		concurrency = len(cgroupPaths)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = collect(cgroupPaths[i])
+			}
+		}()
+	}
+	for i := range cgroupPaths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, errs
+}
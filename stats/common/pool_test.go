@@ -0,0 +1,39 @@
+package common
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectConcurrently(t *testing.T) {
+	// Given
+	paths := []string{"/a", "/b", "/c", "/d", "/e"}
+	var running, maxRunning int32
+
+	// When
+	results, errs := CollectConcurrently(paths, 2, func(path string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if path == "/c" {
+			return "", errors.New("removed")
+		}
+		return path + ".stats", nil
+	})
+
+	// Then
+	assert.Equal(t, []string{"/a.stats", "/b.stats", "", "/d.stats", "/e.stats"}, results)
+	assert.Error(t, errs[2])
+	assert.NoError(t, errs[0])
+	assert.LessOrEqual(t, maxRunning, int32(2))
+}
@@ -30,10 +30,33 @@ func (c *CommonCgroupStatsProvider) ListCgroupsByPrefix(cgroupPrefix string) []s
 
 		_ = filepath.WalkDir(filepath.Dir(prefixPath), func(currPath string, d fs.DirEntry, err error) error {
 			if d.IsDir() && strings.HasPrefix(currPath, prefixPath) {
-				cgroupPaths = append(cgroupPaths, currPath[c.cgroupRootDirLen:])
+				cgroupPath := currPath[c.cgroupRootDirLen:]
+				// The root cgroup is the cgroup root directory itself.
+				if cgroupPath == "" {
+					cgroupPath = "/"
+				}
+				cgroupPaths = append(cgroupPaths, cgroupPath)
 			}
 			return nil
 		})
 	}
 	return cgroupPaths
 }
+
+// CgroupDirs returns the directory of the given cgroup under the given cgroup root directory, followed by the
+// directories of each of its ancestors up to the root directory.
+func CgroupDirs(cgroupRootDir string, cgroupPath string) []string {
+	dirs := []string{cgroupRootDir}
+	elements := strings.Split(strings.Trim(cgroupPath, "/"), "/")
+	for i := range elements {
+		if elements[i] == "" {
+			break
+		}
+		dirs = append(dirs, filepath.Join(cgroupRootDir, filepath.Join(elements[:i+1]...)))
+	}
+	// Order from the cgroup itself up to the root.
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// Names of the counters which are shared by the cgroup v1 and v2 providers.
+const (
+	CPUUsageCounter         = "cpu.usage_usec"
+	ThrottledPeriodsCounter = "cpu.throttled_periods"
+	RunnablePeriodsCounter  = "cpu.runnable_periods"
+	ThrottledUsecCounter    = "cpu.throttled_usec"
+	PageFaultsCounter       = "memory.page_faults"
+	MajorPageFaultsCounter  = "memory.major_page_faults"
+	OomKillsCounter         = "memory.oom_kills"
+)
+
+const usecPerSecond = 1e6
+
+// CounterSample is the value of each monotonically increasing counter of a cgroup at a point in time, keyed by the
+// name of the counter.
+type CounterSample struct {
+	// Time at which the counters were read, in microseconds since the epoch.
+	Time int64
+	// ID identifies the instance of the cgroup, such as the inode of its directory, so that the counters of a cgroup
+	// which was removed and recreated with the same name are not compared. Zero if unknown.
+	ID       uint64
+	Counters map[string]uint64
+}
+
+// NewCounterSample returns an empty sample of the given cgroup instance, read at the given time in microseconds.
+func NewCounterSample(time int64, id uint64) *CounterSample {
+	return &CounterSample{
+		Time:     time,
+		ID:       id,
+		Counters: map[string]uint64{},
+	}
+}
+
+// CounterDeltas is the increase of each counter of a cgroup between two samples. Counters which were reset, or which
+// are missing from either sample, are left out.
+type CounterDeltas struct {
+	// ElapsedUsec is the time between the two samples, in microseconds.
+	ElapsedUsec int64
+	Deltas      map[string]uint64
+}
+
+// Delta returns the increase of the given counter, and whether it is known.
+func (d *CounterDeltas) Delta(name string) (uint64, bool) {
+	if d == nil {
+		return 0, false
+	}
+	delta, ok := d.Deltas[name]
+	return delta, ok
+}
+
+// Rate returns the per-second rate of the given counter, or zero if it is not known.
+func (d *CounterDeltas) Rate(name string) float64 {
+	delta, ok := d.Delta(name)
+	if !ok {
+		return 0.0
+	}
+	return float64(delta) / (float64(d.ElapsedUsec) / usecPerSecond)
+}
+
+// Rates returns the per-second rate of every known counter, or nil if there are none.
+func (d *CounterDeltas) Rates() map[string]float64 {
+	if d == nil || len(d.Deltas) == 0 {
+		return nil
+	}
+	rates := make(map[string]float64, len(d.Deltas))
+	for name := range d.Deltas {
+		rates[name] = d.Rate(name)
+	}
+	return rates
+}
+
+// CounterStore keeps the previous counters of each cgroup, so that providers can report how much each counter
+// increased since the previous sample. It is safe for concurrent use.
+type CounterStore struct {
+	mu       sync.Mutex
+	previous map[string]*CounterSample
+}
+
+func NewCounterStore() *CounterStore {
+	return &CounterStore{
+		previous: map[string]*CounterSample{},
+	}
+}
+
+// Update stores the given sample as the latest sample of the cgroup, and returns the increase of each counter since
+// the previous sample. It returns nil if there is no previous sample, if the cgroup was recreated since, or if no time
+// elapsed.
+func (s *CounterStore) Update(cgroup string, sample *CounterSample) *CounterDeltas {
+	s.mu.Lock()
+	previous := s.previous[cgroup]
+	s.previous[cgroup] = sample
+	s.mu.Unlock()
+
+	if previous == nil || sample.Time <= previous.Time {
+		return nil
+	}
+	if sample.ID != 0 && previous.ID != 0 && sample.ID != previous.ID {
+		return nil
+	}
+	deltas := &CounterDeltas{
+		ElapsedUsec: sample.Time - previous.Time,
+		Deltas:      make(map[string]uint64, len(sample.Counters)),
+	}
+	for name, value := range sample.Counters {
+		// A counter which went backwards was reset, so its increase is unknown.
+		if previousValue, ok := previous.Counters[name]; ok && value >= previousValue {
+			deltas.Deltas[name] = value - previousValue
+		}
+	}
+	return deltas
+}
+
+// Set stores the given sample as the latest sample of the cgroup, such as a sample from a previous run of cgstat.
+func (s *CounterStore) Set(cgroup string, sample *CounterSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.previous[cgroup] = sample
+}
+
+// Retain forgets the samples of every cgroup other than the given cgroups, such as cgroups which were removed since
+// the previous sample, so that the store does not grow on hosts which keep creating short-lived cgroups.
+func (s *CounterStore) Retain(cgroups []string) {
+	retained := make(map[string]bool, len(cgroups))
+	for _, cgroup := range cgroups {
+		retained[cgroup] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for cgroup := range s.previous {
+		if !retained[cgroup] {
+			delete(s.previous, cgroup)
+		}
+	}
+}
+
+// CgroupID returns the inode of the given cgroup directory, which changes when the cgroup is removed and recreated, or
+// zero if it cannot be read.
+func CgroupID(cgroupDir string) uint64 {
+	info, err := os.Stat(cgroupDir)
+	if err != nil {
+		return 0
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return stat.Ino
+	}
+	return 0
+}
+
+// SortedRateNames returns the names of the given rates in alphabetical order.
+func SortedRateNames(rates map[string]float64) []string {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatRate displays a per-second rate, such as "12.5/s".
+func FormatRate(rate float64) string {
+	return fmt.Sprintf("%.1f/s", rate)
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterStore(t *testing.T) {
+	// Given
+	store := NewCounterStore()
+	first := &CounterSample{Time: 1_000_000, ID: 1, Counters: map[string]uint64{
+		PageFaultsCounter: 100, MajorPageFaultsCounter: 10, OomKillsCounter: 1,
+	}}
+
+	t.Run("Report nothing for the first sample.", func(t *testing.T) {
+		assert.Nil(t, store.Update("/a.service", first))
+	})
+
+	t.Run("Report the increase of each counter since the previous sample.", func(t *testing.T) {
+		// When
+		deltas := store.Update("/a.service", &CounterSample{Time: 3_000_000, ID: 1, Counters: map[string]uint64{
+			PageFaultsCounter: 300, MajorPageFaultsCounter: 4, CPUUsageCounter: 1_000_000,
+		}})
+
+		// Then
+		assert.Equal(t, map[string]uint64{PageFaultsCounter: 200}, deltas.Deltas,
+			"Counters which were reset or are new should not be reported.")
+		assert.Equal(t, 100.0, deltas.Rate(PageFaultsCounter))
+		assert.Equal(t, 0.0, deltas.Rate(MajorPageFaultsCounter))
+		assert.Equal(t, map[string]float64{PageFaultsCounter: 100.0}, deltas.Rates())
+	})
+
+	t.Run("Report nothing for a recreated cgroup.", func(t *testing.T) {
+		assert.Nil(t, store.Update("/a.service", &CounterSample{Time: 4_000_000, ID: 2, Counters: map[string]uint64{
+			PageFaultsCounter: 400,
+		}}))
+	})
+
+	t.Run("Forget the samples of cgroups which are not retained.", func(t *testing.T) {
+		// Given
+		store.Set("/b.service", first)
+
+		// When
+		store.Retain([]string{"/b.service"})
+
+		// Then
+		assert.Nil(t, store.Update("/a.service", &CounterSample{Time: 5_000_000, ID: 2, Counters: map[string]uint64{
+			PageFaultsCounter: 500,
+		}}), "A removed cgroup should start over without a rate.")
+		assert.NotNil(t, store.Update("/b.service", &CounterSample{Time: 5_000_000, ID: 1, Counters: map[string]uint64{
+			PageFaultsCounter: 500,
+		}}))
+	})
+}
@@ -0,0 +1,321 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rodaine/table"
+)
+
+const (
+	// RollupSchema is the CgroupVersion of the JSON output of rolled up stats.
+	RollupSchema = "rollup"
+	// TotalRollupName is the name of the rollup containing the stats of the whole host.
+	TotalRollupName = "Total"
+)
+
+// Rollup is the sum of the stats of a group of cgroups.
+type Rollup struct {
+	// Snapshot contains the summed stats, and is named after the group.
+	Snapshot *CgroupSnapshot
+	// NumCgroups is the number of collected cgroups which belong to the group.
+	NumCgroups int
+}
+
+// CgroupDepth returns the depth of the given cgroup in the hierarchy, where the root cgroup has a depth of zero.
+func CgroupDepth(name string) int {
+	trimmed := strings.Trim(name, "/")
+	if trimmed == "" {
+		return 0
+	}
+	return strings.Count(trimmed, "/") + 1
+}
+
+// ancestorAtDepth returns the ancestor of the given cgroup at the given depth, or the cgroup itself if it is at
+// that depth.
+func ancestorAtDepth(name string, depth int) string {
+	elements := strings.Split(strings.Trim(name, "/"), "/")
+	return "/" + strings.Join(elements[:depth], "/")
+}
+
+// RollupSnapshots groups each cgroup at or below the given depth under its ancestor at that depth, and sums the stats
+// of each group. Cgroups above the given depth do not belong to any group. A final rollup contains the total of the
+// host.
+//
+// The stats of a cgroup already include all of its descendants, so only the highest collected cgroups of each group
+// are summed, so that nothing is counted twice. For the same reason, the total only sums cgroups without a collected
+// ancestor.
+func RollupSnapshots(snapshots []*CgroupSnapshot, depth int) ([]*Rollup, *Rollup) {
+	collected := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		collected[snapshot.Name] = true
+	}
+
+	var groups []*Rollup
+	groupsByName := map[string]*Rollup{}
+	total := &Rollup{Snapshot: &CgroupSnapshot{Name: TotalRollupName}}
+
+	for _, snapshot := range snapshots {
+		isTopLevel := !hasCollectedAncestor(snapshot.Name, collected, 0)
+		if isTopLevel {
+			addSnapshot(total.Snapshot, snapshot)
+		}
+		total.NumCgroups++
+
+		if CgroupDepth(snapshot.Name) < depth {
+			continue
+		}
+		groupName := ancestorAtDepth(snapshot.Name, depth)
+		group, ok := groupsByName[groupName]
+		if !ok {
+			group = &Rollup{Snapshot: &CgroupSnapshot{Name: groupName}}
+			groupsByName[groupName] = group
+			groups = append(groups, group)
+		}
+		group.NumCgroups++
+		if !hasCollectedAncestor(snapshot.Name, collected, depth) {
+			addSnapshot(group.Snapshot, snapshot)
+		}
+	}
+	return groups, total
+}
+
+// hasCollectedAncestor returns true if an ancestor of the given cgroup, no higher than minDepth, was collected.
+func hasCollectedAncestor(name string, collected map[string]bool, minDepth int) bool {
+	for depth := CgroupDepth(name) - 1; depth >= minDepth; depth-- {
+		if collected[ancestorAtDepth(name, depth)] {
+			return true
+		}
+	}
+	return false
+}
+
+// addSnapshot adds the counters and gauges of the given snapshot to the total. Limits and peaks cannot be summed, so
+// they are left unset.
+func addSnapshot(total *CgroupSnapshot, s *CgroupSnapshot) {
+	if s.Timestamp.After(total.Timestamp) {
+		total.Timestamp = s.Timestamp
+	}
+	for name, rate := range s.Rates {
+		if total.Rates == nil {
+			total.Rates = map[string]float64{}
+		}
+		total.Rates[name] += rate
+	}
+	if s.CPU != nil {
+		if total.CPU == nil {
+			total.CPU = &CPUSnapshot{}
+		}
+		total.CPU.UsageUsec += s.CPU.UsageUsec
+		total.CPU.Utilization += s.CPU.Utilization
+		total.CPU.ThrottledPeriods += s.CPU.ThrottledPeriods
+		total.CPU.TotalPeriods += s.CPU.TotalPeriods
+		addOptional(&total.CPU.UserUsec, s.CPU.UserUsec)
+		addOptional(&total.CPU.SystemUsec, s.CPU.SystemUsec)
+		addOptional(&total.CPU.ThrottledUsec, s.CPU.ThrottledUsec)
+	}
+	if s.Memory != nil {
+		if total.Memory == nil {
+			total.Memory = &MemorySnapshot{}
+		}
+		total.Memory.Usage += s.Memory.Usage
+		total.Memory.Anon += s.Memory.Anon
+		total.Memory.File += s.Memory.File
+		total.Memory.Kernel += s.Memory.Kernel
+		total.Memory.Dirty += s.Memory.Dirty
+		total.Memory.Writeback += s.Memory.Writeback
+		total.Memory.PageFaults += s.Memory.PageFaults
+		total.Memory.MajorPageFaults += s.Memory.MajorPageFaults
+		addOptional(&total.Memory.Swap, s.Memory.Swap)
+	}
+	if s.PIDs != nil {
+		if total.PIDs == nil {
+			total.PIDs = &PIDSnapshot{}
+		}
+		total.PIDs.Current += s.PIDs.Current
+	}
+	if s.IO != nil {
+		if total.IO == nil {
+			total.IO = &IOSnapshot{Devices: map[string]*IODeviceSnapshot{}}
+		}
+		for deviceName, device := range s.IO.Devices {
+			totalDevice, ok := total.IO.Devices[deviceName]
+			if !ok {
+				totalDevice = &IODeviceSnapshot{}
+				total.IO.Devices[deviceName] = totalDevice
+			}
+			totalDevice.ReadBytes += device.ReadBytes
+			totalDevice.WriteBytes += device.WriteBytes
+			totalDevice.ReadIOs += device.ReadIOs
+			totalDevice.WriteIOs += device.WriteIOs
+		}
+	}
+	if s.Events != nil {
+		if total.Events == nil {
+			total.Events = &EventSnapshot{}
+		}
+		total.Events.OomKills += s.Events.OomKills
+		addOptional(&total.Events.OomEvents, s.Events.OomEvents)
+		addOptional(&total.Events.MemoryHigh, s.Events.MemoryHigh)
+		addOptional(&total.Events.MemoryMax, s.Events.MemoryMax)
+	}
+	if s.Proc != nil {
+		if total.Proc == nil {
+			total.Proc = &ProcSnapshot{}
+		}
+		total.Proc.NumFDs += s.Proc.NumFDs
+		if s.Proc.FDLimitPID != 0 && (total.Proc.FDLimitPID == 0 || s.Proc.FDLimitPercent > total.Proc.FDLimitPercent) {
+			total.Proc.FDLimitPercent = s.Proc.FDLimitPercent
+			total.Proc.FDLimitPID = s.Proc.FDLimitPID
+		}
+	}
+	if s.Network != nil {
+		if total.Network == nil {
+			total.Network = &NetworkSnapshot{}
+		}
+		total.Network.HostNamespace = total.Network.HostNamespace || s.Network.HostNamespace
+		total.Network.UnknownNamespaceProcesses += s.Network.UnknownNamespaceProcesses
+		addNetworkSnapshot(total.Network, s.Network)
+	}
+}
+
+// addNetworkSnapshot adds the sockets and traffic of each network namespace of the given snapshot to the total, unless
+// the namespace was already added through another cgroup. Socket stats describe a whole namespace, so summing them per
+// cgroup would count the namespace once for every cgroup using it. Snapshots without namespaces, such as those of
+// cgroup v1, are summed as they are.
+func addNetworkSnapshot(total *NetworkSnapshot, s *NetworkSnapshot) {
+	if len(s.ByNamespace) == 0 {
+		total.TCPSockets += s.TCPSockets
+		total.UDPSockets += s.UDPSockets
+		total.RxBytes += s.RxBytes
+		total.TxBytes += s.TxBytes
+		return
+	}
+	for _, namespace := range s.ByNamespace {
+		if slices.ContainsFunc(total.ByNamespace, func(other *NetworkNamespaceSnapshot) bool {
+			return other.Inode == namespace.Inode
+		}) {
+			continue
+		}
+		total.ByNamespace = append(total.ByNamespace, namespace)
+		total.TCPSockets += namespace.TCPSockets
+		total.UDPSockets += namespace.UDPSockets
+		total.RxBytes += namespace.RxBytes
+		total.TxBytes += namespace.TxBytes
+	}
+	total.Namespaces = 0
+	for _, namespace := range total.ByNamespace {
+		// The processes whose namespace could not be read are not counted as a namespace.
+		if namespace.Inode != 0 {
+			total.Namespaces++
+		}
+	}
+}
+
+func addOptional(total **uint64, value *uint64) {
+	if value == nil {
+		return
+	}
+	if *total == nil {
+		*total = Uint64(0)
+	}
+	**total += *value
+}
+
+// RollupCollection sums the stats of the given collection by their ancestor at the given depth. Groups are ordered
+// by the given sort keys, and followed by the total of the host.
+func RollupCollection(collection CgroupStatsCollection, depth int, keys []*SortKey) CgroupStatsCollection {
+	groups, total := RollupSnapshots(collection.ToSnapshots(), depth)
+
+	snapshots := make([]*CgroupSnapshot, len(groups))
+	for i, group := range groups {
+		snapshots[i] = group.Snapshot
+	}
+	rollups := make([]*Rollup, 0, len(groups)+1)
+	for _, i := range SortSnapshots(snapshots, keys) {
+		rollups = append(rollups, groups[i])
+	}
+	rollups = append(rollups, total)
+
+	return NewRollupCollection(rollups)
+}
+
+// NewRollupCollection returns a collection of the given rollups.
+func NewRollupCollection(rollups []*Rollup) CgroupStatsCollection {
+	return Collection[*Rollup]{
+		CgroupVersion:            RollupSchema,
+		Stats:                    rollups,
+		CsvHeadersProvider:       getRollupCSVHeaders,
+		CsvRowTransformer:        toRollupCSVRow,
+		DisplayHeadersProvider:   getRollupDisplayHeaders,
+		DisplayRowTransformer:    toRollupDisplayRow,
+		VerboseOutputTransformer: toRollupVerboseOutput,
+		MetricsTransformer:       toRollupMetrics,
+		SnapshotTransformer: func(r *Rollup) *CgroupSnapshot {
+			return r.Snapshot
+		},
+	}
+}
+
+func getRollupCSVHeaders() []string {
+	headers := []string{"Timestamp", "Name", "Cgroups"}
+	for _, column := range SnapshotColumns[NameColumn+1:] {
+		headers = append(headers, column.Field.Name)
+	}
+	return headers
+}
+
+func toRollupCSVRow(r *Rollup) []string {
+	t, _ := r.Snapshot.Timestamp.UTC().MarshalText()
+	row := []string{string(t), r.Snapshot.Name, strconv.Itoa(r.NumCgroups)}
+	for _, column := range SnapshotColumns[NameColumn+1:] {
+		row = append(row, strconv.FormatFloat(column.Field.Value(r.Snapshot), 'f', -1, 64))
+	}
+	return row
+}
+
+func getRollupDisplayHeaders() []interface{} {
+	headers := []interface{}{"Name", "Cgroups"}
+	for _, column := range SnapshotColumns[NameColumn+1:] {
+		headers = append(headers, column.Header)
+	}
+	return headers
+}
+
+func toRollupDisplayRow(r *Rollup) []interface{} {
+	row := []interface{}{Shorten(r.Snapshot.Name, 32), fmt.Sprintf("%d", r.NumCgroups)}
+	for _, column := range SnapshotColumns[NameColumn+1:] {
+		row = append(row, column.Format(r.Snapshot))
+	}
+	return row
+}
+
+func toRollupVerboseOutput(w io.Writer, rollups []*Rollup) {
+	tbl := table.New()
+	tbl.WithWriter(w)
+	for _, r := range rollups {
+		tbl.AddRow("Name:", r.Snapshot.Name)
+		tbl.AddRow("Cgroups:", r.NumCgroups)
+		for _, column := range SnapshotColumns[NameColumn+1:] {
+			tbl.AddRow(column.Header+":", column.Format(r.Snapshot))
+		}
+	}
+	tbl.Print()
+}
+
+func toRollupMetrics(r *Rollup) *CgroupMetrics {
+	metrics := []*Metric{
+		NewGauge("rollup_cgroups", "Number of cgroups in the group.", float64(r.NumCgroups)),
+	}
+	for _, column := range SnapshotColumns[NameColumn+1:] {
+		metrics = append(metrics, NewGauge("rollup_"+column.Field.Name, column.Header+" of the group.",
+			column.Field.Value(r.Snapshot)))
+	}
+	return &CgroupMetrics{
+		Name:    r.Snapshot.Name,
+		Metrics: metrics,
+	}
+}
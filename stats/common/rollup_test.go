@@ -0,0 +1,83 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupSnapshots(t *testing.T) {
+	// Given
+	newSnapshot := func(name string, memory uint64) *CgroupSnapshot {
+		return &CgroupSnapshot{
+			Name:   name,
+			Memory: &MemorySnapshot{Usage: memory, Limit: 1024},
+			PIDs:   &PIDSnapshot{Current: 1},
+		}
+	}
+	snapshots := []*CgroupSnapshot{
+		newSnapshot("/system.slice", 100),
+		newSnapshot("/system.slice/a.service", 60),
+		newSnapshot("/system.slice/a.service/child", 20),
+		newSnapshot("/system.slice/b.service", 40),
+		newSnapshot("/user.slice/user-1.slice", 10),
+	}
+
+	// When
+	groups, total := RollupSnapshots(snapshots, 2)
+
+	// Then
+	assert.Len(t, groups, 3)
+	assert.Equal(t, "/system.slice/a.service", groups[0].Snapshot.Name)
+	assert.Equal(t, 2, groups[0].NumCgroups)
+	assert.Equal(t, uint64(60), groups[0].Snapshot.Memory.Usage, "Descendants are already included in the stats.")
+	assert.Equal(t, uint64(0), groups[0].Snapshot.Memory.Limit, "Limits are not summed.")
+	assert.Equal(t, "/user.slice/user-1.slice", groups[2].Snapshot.Name)
+
+	assert.Equal(t, TotalRollupName, total.Snapshot.Name)
+	assert.Equal(t, 5, total.NumCgroups)
+	assert.Equal(t, uint64(110), total.Snapshot.Memory.Usage)
+	assert.Equal(t, uint64(2), total.Snapshot.PIDs.Current)
+}
+
+func TestRollupSnapshots_SharedNetworkNamespace(t *testing.T) {
+	// Given
+	host := &NetworkNamespaceSnapshot{Inode: 4026531840, TCPSockets: 500, UDPSockets: 20}
+	container := &NetworkNamespaceSnapshot{Inode: 4026532000, TCPSockets: 5, UDPSockets: 1, RxBytes: 100, TxBytes: 50}
+	newSnapshot := func(name string, namespaces ...*NetworkNamespaceSnapshot) *CgroupSnapshot {
+		network := &NetworkSnapshot{Namespaces: len(namespaces), ByNamespace: namespaces}
+		for _, namespace := range namespaces {
+			network.TCPSockets += namespace.TCPSockets
+			network.UDPSockets += namespace.UDPSockets
+			network.RxBytes += namespace.RxBytes
+		}
+		return &CgroupSnapshot{Name: name, Network: network}
+	}
+	snapshots := []*CgroupSnapshot{
+		newSnapshot("/system.slice/a.service", host),
+		newSnapshot("/system.slice/b.service", host),
+		newSnapshot("/system.slice/c.service", host, container),
+		newSnapshot("/system.slice/d.service", container),
+		newSnapshot("/system.slice/e.service", &NetworkNamespaceSnapshot{TCPSockets: 7, UDPSockets: 3}),
+	}
+	snapshots[4].Network.Namespaces = 0
+	snapshots[4].Network.UnknownNamespaceProcesses = 2
+
+	// When
+	groups, total := RollupSnapshots(snapshots, 1)
+
+	// Then
+	assert.Len(t, groups, 1)
+	assert.Equal(t, uint64(512), groups[0].Snapshot.Network.TCPSockets, "Each namespace should only be counted once.")
+	assert.Equal(t, uint64(24), groups[0].Snapshot.Network.UDPSockets)
+	assert.Equal(t, uint64(100), groups[0].Snapshot.Network.RxBytes)
+	assert.Equal(t, 2, groups[0].Snapshot.Network.Namespaces, "The unknown namespace should not be counted as a namespace.")
+	assert.Equal(t, uint64(2), groups[0].Snapshot.Network.UnknownNamespaceProcesses)
+	assert.Equal(t, uint64(512), total.Snapshot.Network.TCPSockets)
+}
+
+func TestCgroupDepth(t *testing.T) {
+	assert.Equal(t, 0, CgroupDepth("/"))
+	assert.Equal(t, 1, CgroupDepth("/system.slice"))
+	assert.Equal(t, 2, CgroupDepth("/system.slice/a.service/"))
+}
@@ -0,0 +1,171 @@
+package common
+
+import "time"
+
+// CgroupSnapshot is a typed, cgroup version independent view of the stats of a single cgroup. Both the cgroup v1 and
+// v2 providers populate it, so that consumers can work on raw values regardless of the hierarchy used by the host.
+// A section is nil if it is not supported by the cgroup version or if it could not be read.
+type CgroupSnapshot struct {
+	Name string
+	// Timestamp is the time at which the stats were read.
+	Timestamp time.Time
+	CPU       *CPUSnapshot
+	Memory    *MemorySnapshot
+	PIDs      *PIDSnapshot
+	IO        *IOSnapshot
+	Events    *EventSnapshot
+	Proc      *ProcSnapshot
+	Network   *NetworkSnapshot
+	// Rates contains the per-second rate of each counter since the previous sample, keyed by the name of the counter.
+	// It is nil for the first sample of a cgroup.
+	Rates map[string]float64
+}
+
+type CPUSnapshot struct {
+	// UsageUsec is the total CPU time consumed by the cgroup, in microseconds.
+	UsageUsec uint64
+	// UserUsec is the userspace CPU time consumed by the cgroup, in microseconds. Only reported by cgroup v2.
+	UserUsec *uint64
+	// SystemUsec is the kernel CPU time consumed by the cgroup, in microseconds. Only reported by cgroup v2.
+	SystemUsec *uint64
+	// Utilization is the percentage of a single CPU used by the cgroup since the previous sample.
+	Utilization float64
+	// ThrottledPeriods is the number of periods in which the cgroup was throttled.
+	ThrottledPeriods uint64
+	// TotalPeriods is the number of periods in which the cgroup was runnable.
+	TotalPeriods uint64
+	// ThrottledUsec is the total time the cgroup was throttled, in microseconds.
+	ThrottledUsec *uint64
+	// Limit is the CPU bandwidth available to the cgroup, if known.
+	Limit *CPULimit
+	// Throttling is the throttling of the cgroup since the previous sample, if there was one.
+	Throttling *ThrottlingRate
+}
+
+type MemorySnapshot struct {
+	// Usage is the amount of memory used by the cgroup and its descendants, in bytes.
+	Usage uint64
+	// Limit is the maximum amount of memory that can be used by the cgroup and its descendants, in bytes.
+	Limit uint64
+	// MaxUsage is the maximum amount of memory that has been used by the cgroup. Only reported by cgroup v1.
+	MaxUsage *uint64
+	// Anon is the amount of anonymous memory, such as the stack and heap. This is the RSS in cgroup v1.
+	Anon uint64
+	// File is the amount of memory used to cache filesystem data.
+	File uint64
+	// Kernel is the amount of memory used by the kernel on behalf of the cgroup.
+	Kernel uint64
+	// Swap is the amount of swap used by the cgroup. Only reported by cgroup v2.
+	Swap *uint64
+	// Dirty is the amount of cached filesystem data waiting to be written back to disk.
+	Dirty uint64
+	// Writeback is the amount of cached filesystem data actively being written back to disk.
+	Writeback uint64
+	// PageFaults is the total number of page faults incurred.
+	PageFaults uint64
+	// MajorPageFaults is the total number of major page faults incurred.
+	MajorPageFaults uint64
+}
+
+// UsagePercent returns the memory usage as a percentage of the limit. Zero is returned if there is no limit.
+func (m *MemorySnapshot) UsagePercent() float64 {
+	if m.Limit == 0 {
+		return 0.0
+	}
+	return float64(m.Usage) / float64(m.Limit) * 100.0
+}
+
+type PIDSnapshot struct {
+	// Current is the number of processes in the cgroup and its descendants.
+	Current uint64
+	// Limit is the maximum number of processes. Zero if unknown.
+	Limit uint64
+}
+
+type IODeviceSnapshot struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
+}
+
+type IOSnapshot struct {
+	// Devices contains the IO stats of each block device, keyed by device name.
+	Devices map[string]*IODeviceSnapshot
+}
+
+// Total returns the sum of the IO stats across all block devices.
+func (i *IOSnapshot) Total() *IODeviceSnapshot {
+	total := &IODeviceSnapshot{}
+	for _, device := range i.Devices {
+		total.ReadBytes += device.ReadBytes
+		total.WriteBytes += device.WriteBytes
+		total.ReadIOs += device.ReadIOs
+		total.WriteIOs += device.WriteIOs
+	}
+	return total
+}
+
+type EventSnapshot struct {
+	// OomKills is the number of processes in the cgroup killed by the OOM killer.
+	OomKills uint64
+	// OomEvents is the number of times the cgroup reached its memory limit. Only reported by cgroup v2.
+	OomEvents *uint64
+	// MemoryHigh is the number of times the cgroup exceeded its high memory boundary. Only reported by cgroup v2.
+	MemoryHigh *uint64
+	// MemoryMax is the number of times the cgroup was about to exceed its memory limit. Only reported by cgroup v2.
+	MemoryMax *uint64
+	// UnderOom is true if the cgroup is currently under OOM. Only reported by cgroup v1.
+	UnderOom *bool
+}
+
+type ProcSnapshot struct {
+	// NumFDs is the number of open file descriptors of the processes in the cgroup.
+	NumFDs uint64
+	// FDLimitPercent is the highest number of open file descriptors of a process in the cgroup, as a percentage of its
+	// RLIMIT_NOFILE. Only reported by cgroup v2.
+	FDLimitPercent float64
+	// FDLimitPID is the process with the highest FDLimitPercent, or zero if no process has a known limit.
+	FDLimitPID int
+}
+
+type NetworkSnapshot struct {
+	// TCPSockets is the number of TCP sockets in use.
+	TCPSockets uint64
+	// UDPSockets is the number of UDP sockets in use.
+	UDPSockets uint64
+	// Namespaces is the number of network namespaces used by the cgroup. Sockets are counted once per namespace.
+	Namespaces int
+	// HostNamespace is true if the cgroup uses the network namespace of the host, in which case the sockets include
+	// every socket of the host.
+	HostNamespace bool
+	// RxBytes and TxBytes are the bytes received and transmitted by the network interfaces of the cgroup, other than
+	// loopback interfaces. Only reported by cgroup v2, for network namespaces other than the host namespace.
+	RxBytes uint64
+	TxBytes uint64
+	// UnknownNamespaceProcesses is the number of processes whose network namespace could not be read, such as those
+	// of other users when not running as root. Their sockets are counted as if they shared a single namespace.
+	UnknownNamespaceProcesses uint64 `json:",omitempty"`
+	// ByNamespace contains the sockets and traffic of each network namespace used by the cgroup, so that cgroups
+	// sharing a namespace are only counted once when they are summed. Only reported by cgroup v2.
+	ByNamespace []*NetworkNamespaceSnapshot `json:",omitempty"`
+}
+
+type NetworkNamespaceSnapshot struct {
+	// Inode of the network namespace, or zero for the processes whose namespace could not be read.
+	Inode      uint64
+	TCPSockets uint64
+	UDPSockets uint64
+	RxBytes    uint64
+	TxBytes    uint64
+}
+
+// Uint64 returns a pointer to the given value, which is used to populate optional fields.
+func Uint64(v uint64) *uint64 {
+	return &v
+}
+
+// Bool returns a pointer to the given value, which is used to populate optional fields.
+func Bool(v bool) *bool {
+	return &v
+}
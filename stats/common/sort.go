@@ -0,0 +1,88 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+type SortKey struct {
+	Field      *SnapshotField
+	Descending bool
+}
+
+// ParseSortKeys parses a comma-separated list of sort keys, such as "memory:desc,cpu". Each key is a field name,
+// optionally followed by ":asc" or ":desc". Numeric fields are sorted in descending order by default so that the
+// largest consumers come first, while names are sorted in ascending order.
+func ParseSortKeys(spec string) ([]*SortKey, error) {
+	var keys []*SortKey
+	for _, keySpec := range strings.Split(spec, ",") {
+		fieldName, order, hasOrder := strings.Cut(strings.TrimSpace(keySpec), ":")
+		field, ok := SnapshotFields[fieldName]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q, must be one of: %s", fieldName, sortFieldNames())
+		}
+		key := &SortKey{
+			Field:      field,
+			Descending: field.Value != nil,
+		}
+		if hasOrder {
+			switch order {
+			case SortAscending:
+				key.Descending = false
+			case SortDescending:
+				key.Descending = true
+			default:
+				return nil, fmt.Errorf("unknown sort order %q, must be %s or %s", order, SortAscending, SortDescending)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func sortFieldNames() string {
+	names := make([]string, 0, len(SnapshotFields))
+	for name := range SnapshotFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// SortSnapshots returns the indices of the given snapshots ordered by the given keys. Ties are broken by cgroup name.
+func SortSnapshots(snapshots []*CgroupSnapshot, keys []*SortKey) []int {
+	indices := make([]int, len(snapshots))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := snapshots[indices[i]], snapshots[indices[j]]
+		for _, key := range keys {
+			cmp := key.Field.Compare(a, b)
+			if key.Descending {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return a.Name < b.Name
+	})
+	return indices
+}
+
+// SortCollection orders the given collection by the given keys, and keeps only the first top cgroups if top is
+// greater than zero.
+func SortCollection(collection CgroupStatsCollection, keys []*SortKey, top int) CgroupStatsCollection {
+	indices := SortSnapshots(collection.ToSnapshots(), keys)
+	if top > 0 && top < len(indices) {
+		indices = indices[:top]
+	}
+	return collection.Select(indices)
+}
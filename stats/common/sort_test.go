@@ -0,0 +1,51 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	// When
+	keys, err := ParseSortKeys("memory:asc,cpu, name")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []*SortKey{
+		{Field: MemoryField, Descending: false},
+		{Field: CPUField, Descending: true},
+		{Field: NameField, Descending: false},
+	}, keys)
+}
+
+func TestParseSortKeys_Invalid(t *testing.T) {
+	_, err := ParseSortKeys("disk")
+	assert.Error(t, err, "Unknown fields should be rejected.")
+
+	_, err = ParseSortKeys("cpu:up")
+	assert.Error(t, err, "Unknown orders should be rejected.")
+}
+
+func TestSortCollection(t *testing.T) {
+	// Given
+	snapshots := map[string]*CgroupSnapshot{
+		"a": {Name: "a", CPU: &CPUSnapshot{Utilization: 10}, Memory: &MemorySnapshot{Usage: 100}},
+		"b": {Name: "b", CPU: &CPUSnapshot{Utilization: 50}, Memory: &MemorySnapshot{Usage: 100}},
+		"c": {Name: "c", CPU: &CPUSnapshot{Utilization: 30}, Memory: &MemorySnapshot{Usage: 300}},
+		"d": {Name: "d"},
+	}
+	collection := Collection[string]{
+		Stats: []string{"a", "b", "c", "d"},
+		SnapshotTransformer: func(s string) *CgroupSnapshot {
+			return snapshots[s]
+		},
+	}
+	keys := []*SortKey{{Field: MemoryField, Descending: true}, {Field: CPUField, Descending: false}}
+
+	// When
+	sorted := SortCollection(collection, keys, 3)
+
+	// Then
+	assert.Equal(t, []string{"c", "a", "b"}, sorted.(Collection[string]).Stats)
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// TreeEntry is a cgroup placed in the cgroup hierarchy.
+type TreeEntry struct {
+	// Index of the cgroup in the list of names the tree was built from.
+	Index int
+	Name  string
+	// Depth is the number of ancestors of the cgroup which are part of the tree. Roots have a depth of zero.
+	Depth int
+	// HasChildren is true if other cgroups in the tree are nested under this one.
+	HasChildren bool
+}
+
+type treeNode struct {
+	index    int
+	children []*treeNode
+}
+
+// BuildTree nests each of the given cgroups under its closest ancestor which is also part of the list, and returns
+// the cgroups in depth-first order. Siblings are ordered using the given less function, which compares the cgroups at
+// the given indices. Cgroups deeper than maxDepth are omitted unless maxDepth is negative, and the descendants of
+// collapsed cgroups are omitted.
+func BuildTree(names []string, less func(i, j int) bool, maxDepth int, collapsed map[string]bool) []*TreeEntry {
+	nodesByName := make(map[string]*treeNode, len(names))
+	for i, name := range names {
+		nodesByName[name] = &treeNode{index: i}
+	}
+	var roots []*treeNode
+	for _, name := range names {
+		node := nodesByName[name]
+		if parent := findTreeParent(name, nodesByName); parent != nil {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var entries []*TreeEntry
+	var visit func(nodes []*treeNode, depth int)
+	visit = func(nodes []*treeNode, depth int) {
+		if maxDepth >= 0 && depth > maxDepth {
+			return
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return less(nodes[i].index, nodes[j].index)
+		})
+		for _, node := range nodes {
+			name := names[node.index]
+			entries = append(entries, &TreeEntry{
+				Index:       node.index,
+				Name:        name,
+				Depth:       depth,
+				HasChildren: len(node.children) > 0,
+			})
+			if !collapsed[name] {
+				visit(node.children, depth+1)
+			}
+		}
+	}
+	visit(roots, 0)
+
+	return entries
+}
+
+// findTreeParent returns the closest ancestor of the given cgroup which is part of the given nodes.
+func findTreeParent(name string, nodesByName map[string]*treeNode) *treeNode {
+	for name != "/" && name != "." && name != "" {
+		name = path.Dir(name)
+		if parent, ok := nodesByName[name]; ok {
+			return parent
+		}
+	}
+	return nil
+}
+
+// TreeDisplayName returns the name of the cgroup indented by its depth. Only the last element of the path is shown
+// for nested cgroups, since the rest of the path is given by its ancestors.
+func TreeDisplayName(entry *TreeEntry) string {
+	name := entry.Name
+	if entry.Depth > 0 {
+		name = path.Base(name)
+	}
+	return strings.Repeat("  ", entry.Depth) + name
+}
+
+// TreeCollection orders the given collection in depth-first order of the cgroup hierarchy, ordering siblings by the
+// given sort keys, and omits cgroups deeper than maxDepth unless it is negative.
+func TreeCollection(collection CgroupStatsCollection, keys []*SortKey, maxDepth int) CgroupStatsCollection {
+	snapshots := collection.ToSnapshots()
+	names := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		names[i] = snapshot.Name
+	}
+
+	// Sort once up front, and use the rank of each cgroup to order siblings.
+	rank := make([]int, len(snapshots))
+	for i, index := range SortSnapshots(snapshots, keys) {
+		rank[index] = i
+	}
+	entries := BuildTree(names, func(i, j int) bool {
+		return rank[i] < rank[j]
+	}, maxDepth, nil)
+
+	indices := make([]int, len(entries))
+	for i, entry := range entries {
+		indices[i] = entry.Index
+	}
+	return collection.Select(indices)
+}
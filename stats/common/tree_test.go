@@ -0,0 +1,41 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTree(t *testing.T) {
+	// Given
+	names := []string{"/system.slice/b.service", "/system.slice", "/system.slice/a.service",
+		"/system.slice/a.service/child", "/user.slice/user-1.slice"}
+	byName := func(i, j int) bool {
+		return names[i] < names[j]
+	}
+
+	t.Run("Nest cgroups under their closest ancestor.", func(t *testing.T) {
+		entries := BuildTree(names, byName, -1, nil)
+
+		assert.Equal(t, []*TreeEntry{
+			{Index: 1, Name: "/system.slice", Depth: 0, HasChildren: true},
+			{Index: 2, Name: "/system.slice/a.service", Depth: 1, HasChildren: true},
+			{Index: 3, Name: "/system.slice/a.service/child", Depth: 2},
+			{Index: 0, Name: "/system.slice/b.service", Depth: 1},
+			{Index: 4, Name: "/user.slice/user-1.slice", Depth: 0},
+		}, entries)
+		assert.Equal(t, "  a.service", TreeDisplayName(entries[1]))
+	})
+
+	t.Run("Omit cgroups deeper than the max depth.", func(t *testing.T) {
+		entries := BuildTree(names, byName, 1, nil)
+
+		assert.Len(t, entries, 4)
+	})
+
+	t.Run("Omit the descendants of collapsed cgroups.", func(t *testing.T) {
+		entries := BuildTree(names, byName, -1, map[string]bool{"/system.slice": true})
+
+		assert.Len(t, entries, 2)
+	})
+}
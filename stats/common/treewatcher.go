@@ -0,0 +1,150 @@
+package common
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TreeWatcher uses inotify to watch the cgroup hierarchy for cgroups which are created or removed, so that even
+// cgroups which only exist between two samples are noticed.
+type TreeWatcher struct {
+	cgroupRootDir string
+	// prefixPath is the path of the cgroup prefix. Only cgroups with this prefix are reported.
+	prefixPath string
+	watcher    *fsnotify.Watcher
+	// watched contains the path of every watched cgroup directory.
+	watched map[string]bool
+}
+
+// NewTreeWatcher watches every cgroup with the given prefix under the given cgroup root directory, along with the
+// parent of the prefix, so that new cgroups matching the prefix are noticed. The parent of the root cgroup is not a
+// cgroup, so when the prefix is the root cgroup, only the root cgroup and its descendants are watched.
+func NewTreeWatcher(cgroupRootDir string, cgroupPrefix string) (*TreeWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create watcher: %w", err)
+	}
+
+	w := &TreeWatcher{
+		cgroupRootDir: filepath.Clean(cgroupRootDir),
+		prefixPath:    filepath.Join(cgroupRootDir, cgroupPrefix),
+		watcher:       watcher,
+		watched:       map[string]bool{},
+	}
+	dir := filepath.Dir(w.prefixPath)
+	if w.prefixPath == w.cgroupRootDir {
+		dir = w.prefixPath
+	}
+	if err = w.watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("could not watch %s: %w", dir, err)
+	}
+	w.watchTree(dir)
+	return w, nil
+}
+
+// watchTree watches every cgroup directory with the prefix under the given directory, and returns their paths.
+// Directories without the prefix below the given directory are skipped along with their descendants.
+func (w *TreeWatcher) watchTree(dir string) []string {
+	var added []string
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || w.watched[path] {
+			return nil
+		}
+		if !strings.HasPrefix(path, w.prefixPath) {
+			if path == dir {
+				return nil
+			}
+			return filepath.SkipDir
+		}
+		// The cgroup may already have been removed, in which case it is still reported, and its removal is reported
+		// by its parent.
+		_ = w.watcher.Add(path)
+		w.watched[path] = true
+		added = append(added, path)
+		return nil
+	})
+	return added
+}
+
+// watchCreated watches a cgroup directory which was just created, along with its descendants, and returns their
+// paths. A cgroup which was removed before it could be watched is returned as well, so that it is not missed.
+func (w *TreeWatcher) watchCreated(path string) []string {
+	if !strings.HasPrefix(path, w.prefixPath) || w.watched[path] {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		w.watched[path] = true
+		return []string{path}
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	return w.watchTree(path)
+}
+
+// Run calls the given handler with the name of each cgroup which is created or removed, until the watcher is closed or
+// the handler returns an error. A cgroup is reported as created along with any descendants which were created before
+// it could be watched.
+func (w *TreeWatcher) Run(handler func(eventType LifecycleEventType, cgroup string) error) error {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch {
+			case event.Has(fsnotify.Create):
+				for _, path := range w.watchCreated(event.Name) {
+					if err := handler(CgroupCreated, w.toCgroupName(path)); err != nil {
+						return err
+					}
+				}
+			case event.Has(fsnotify.Remove):
+				// A removal is reported both by the directory itself and by its parent.
+				if w.watched[event.Name] {
+					delete(w.watched, event.Name)
+					_ = w.watcher.Remove(event.Name)
+					if err := handler(CgroupRemoved, w.toCgroupName(event.Name)); err != nil {
+						return err
+					}
+				}
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("error watching cgroups: %w", err)
+		}
+	}
+}
+
+// Cgroups returns the names of the cgroups being watched, ordered by name. It must not be called while Run is running.
+func (w *TreeWatcher) Cgroups() []string {
+	cgroups := make([]string, 0, len(w.watched))
+	for path := range w.watched {
+		cgroups = append(cgroups, w.toCgroupName(path))
+	}
+	sort.Strings(cgroups)
+	return cgroups
+}
+
+func (w *TreeWatcher) toCgroupName(path string) string {
+	name := strings.TrimPrefix(path, w.cgroupRootDir)
+	if name == "" {
+		return "/"
+	}
+	return name
+}
+
+// Close stops watching the cgroup hierarchy.
+func (w *TreeWatcher) Close() error {
+	return w.watcher.Close()
+}
@@ -1,19 +1,44 @@
 package stats
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/containerd/cgroups/v3"
 	"github.com/strategicpause/cgstat/stats/common"
 	v1 "github.com/strategicpause/cgstat/stats/v1"
 	v2 "github.com/strategicpause/cgstat/stats/v2"
 )
 
-func NewCgroupStatsProvider() common.CgroupStatsProvider {
-	if isCgroupsV2Enabled() {
-		return v2.NewCgroupStatsProvider()
+func NewCgroupStatsProvider(opts ...common.ProviderOpt) common.CgroupStatsProvider {
+	if IsCgroupsV2Enabled() {
+		return v2.NewCgroupStatsProvider(opts...)
+	}
+	return v1.NewCgroupStatsProvider(opts...)
+}
+
+// CgroupRootDir returns the directory of the cgroup hierarchy in which cgroups are listed.
+func CgroupRootDir() string {
+	if IsCgroupsV2Enabled() {
+		return v2.CgroupPrefix
 	}
-	return v1.NewCgroupStatsProvider()
+	return v1.CgroupPrefix
 }
 
-func isCgroupsV2Enabled() bool {
+// IsCgroupsV2Enabled returns true if the host uses the unified cgroup v2 hierarchy.
+func IsCgroupsV2Enabled() bool {
 	return cgroups.Mode() == cgroups.Unified
 }
+
+// DecodeCollection will decode the JSON encoded Cgroups of a JSONOutput with the given cgroup version, which were
+// collected at the given time.
+func DecodeCollection(cgroupVersion string, timestamp time.Time, data []byte) (common.CgroupStatsCollection, error) {
+	switch cgroupVersion {
+	case common.CgroupV1:
+		return v1.DecodeCollection(timestamp, data)
+	case common.CgroupV2:
+		return v2.DecodeCollection(timestamp, data)
+	default:
+		return nil, fmt.Errorf("unsupported cgroup version %q", cgroupVersion)
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/strategicpause/cgstat/stats/common"
 	"io"
@@ -9,26 +10,45 @@ import (
 )
 
 func NewCollection(stats []*CgroupStats) common.CgroupStatsCollection {
+	return newCollection(stats)
+}
+
+// DecodeCollection will decode the JSON encoded Cgroups of a JSONOutput, which were collected at the given time.
+func DecodeCollection(timestamp time.Time, data []byte) (common.CgroupStatsCollection, error) {
+	var stats []*CgroupStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("could not decode v1 cgroup stats: %w", err)
+	}
+	collection := newCollection(stats)
+	collection.Timestamp = timestamp
+	return collection, nil
+}
+
+func newCollection(stats []*CgroupStats) common.Collection[*CgroupStats] {
 	return common.Collection[*CgroupStats]{
+		CgroupVersion:            common.CgroupV1,
 		Stats:                    stats,
 		CsvHeadersProvider:       getCSVHeaders,
 		CsvRowTransformer:        toCSVRow,
 		DisplayHeadersProvider:   getDisplayHeaders,
 		DisplayRowTransformer:    toDisplayRow,
 		VerboseOutputTransformer: toVerboseOutput,
+		MetricsTransformer:       toMetrics,
+		SnapshotTransformer:      toSnapshot,
 	}
 }
 
 func getCSVHeaders() []string {
 	return []string{
 		"Time", "Name", "UserCPU", "CurrentUsage", "MaxUsage", "UsageLimit", "RSS",
-		"Cache", "Dirty", "WriteBack", "UnderOom", "OomKill",
+		"Cache", "Dirty", "WriteBack", "UnderOom", "OomKill", "CPUCores", "CPULimitCores", "CPULimitUsage",
+		"ThrottledPercent", "ThrottledPeriodsPerSec", "ThrottledUsecPerSec", "PgFaultPerSec", "PgMajFaultPerSec",
 	}
 }
 
 func toCSVRow(c *CgroupStats) []string {
-	t, _ := time.Now().UTC().MarshalText()
-	return []string{
+	t, _ := time.UnixMicro(c.SystemTime).UTC().MarshalText()
+	row := []string{
 		string(t),
 		c.Name,
 		fmt.Sprintf("%f", c.CPUUtilization),
@@ -41,18 +61,28 @@ func toCSVRow(c *CgroupStats) []string {
 		fmt.Sprintf("%d", c.WriteBack),
 		fmt.Sprintf("%d", c.UnderOom),
 		fmt.Sprintf("%d", c.OomKill),
+		fmt.Sprintf("%f", c.CPUUtilization/100.0),
+		fmt.Sprintf("%f", c.CPULimit.GetCores()),
+		fmt.Sprintf("%f", c.CPULimit.UsagePercent(c.CPUUtilization)),
 	}
+	row = append(row, common.ThrottlingRateCSVColumns(c.Throttling)...)
+	return append(row,
+		fmt.Sprintf("%f", c.Rates[common.PageFaultsCounter]),
+		fmt.Sprintf("%f", c.Rates[common.MajorPageFaultsCounter]),
+	)
 }
 
 func getDisplayHeaders() []interface{} {
 	return []interface{}{
-		"Name", "CPU", "NumProcesses", "CurrentUsage", "MaxUsage", "UsageLimit",
-		"RSS", "Cache", "Dirty", "WriteBack", "UnderOom", "OomKill",
+		"Name", "CPU", "CPUCores / Limit", "Throttled", "NumProcesses", "CurrentUsage", "MaxUsage", "UsageLimit",
+		"RSS", "Cache", "Dirty", "WriteBack", "PgFault/s (Major)", "UnderOom", "OomKill",
 	}
 }
 
 func toDisplayRow(c *CgroupStats) []interface{} {
 	CPU := fmt.Sprintf("%.2f%%", c.CPUUtilization)
+	cpuCores := common.FormatCPUCores(c.CPUUtilization, c.CPULimit)
+	throttled := common.FormatThrottlingRate(c.Throttling)
 	numProcess := fmt.Sprintf("%d", c.NumProcesses)
 	currentUsage := fmt.Sprintf("%s (%.2f%%)", common.FormatBytes(c.CurrentUsage), c.CurrentUtilization)
 	maxUsage := fmt.Sprintf("%s (%.2f%%)", common.FormatBytes(c.MaxUsage), c.MaxUtilization)
@@ -61,11 +91,13 @@ func toDisplayRow(c *CgroupStats) []interface{} {
 	cacheSize := common.FormatBytes(c.CacheSize)
 	dirtySize := common.FormatBytes(c.DirtySize)
 	writeback := common.FormatBytes(c.WriteBack)
+	pageFaults := fmt.Sprintf("%s (%s)", common.FormatRate(c.Rates[common.PageFaultsCounter]),
+		common.FormatRate(c.Rates[common.MajorPageFaultsCounter]))
 	underOom := fmt.Sprintf("%d", c.UnderOom)
 	oomKill := fmt.Sprintf("%d", c.OomKill)
 
-	return []interface{}{c.Name, CPU, numProcess, currentUsage, maxUsage, usageLimit, rss,
-		cacheSize, dirtySize, writeback, underOom, oomKill}
+	return []interface{}{c.Name, CPU, cpuCores, throttled, numProcess, currentUsage, maxUsage, usageLimit, rss,
+		cacheSize, dirtySize, writeback, pageFaults, underOom, oomKill}
 }
 
 func toVerboseOutput(w io.Writer, c []*CgroupStats) {
@@ -73,6 +105,7 @@ func toVerboseOutput(w io.Writer, c []*CgroupStats) {
 		printMemStats(w, cgropStats)
 		printCPUStats(w, cgropStats)
 		printBlkIOStats(w, cgropStats)
+		printRates(w, cgropStats)
 	}
 }
 
@@ -86,8 +119,8 @@ func printMemStats(writer io.Writer, s *CgroupStats) {
 	printMemStat(writer, "Writeback", s.WriteBack)
 	printMemStat(writer, "Cache", s.CacheSize)
 	printMemStat(writer, "Dirty", s.DirtySize)
-	printMemStat(writer, "PgPgIn", s.PgPgIn)
-	printMemStat(writer, "PgPgOut", s.PgPgOut)
+	printCounter(writer, "PgPgIn", s.PgPgIn)
+	printCounter(writer, "PgPgOut", s.PgPgOut)
 	printCounter(writer, "PgFault", s.PgFault)
 	printCounter(writer, "PgMajFault", s.PgMajFault)
 	printMemStat(writer, "ActiveAnon", s.ActiveAnon)
@@ -129,15 +162,33 @@ func printCPUStats(w io.Writer, s *CgroupStats) {
 	fmt.Fprintln(w, "CPU Stats")
 
 	printCpuStat(w, "CPU", s.CPUUtilization)
+	printStringStat(w, "CPUCores", common.FormatCPUCores(s.CPUUtilization, s.CPULimit))
+	printStringStat(w, "CPULimit", s.CPULimit.String())
 	printCounter(w, "NumProcesses", s.NumProcesses)
 	printCounter(w, "ThrottlePeriods", s.ThrottlePeriods)
 	printCounter(w, "TotalPeriods", s.TotalPeriods)
+	printCounter(w, "ThrottledTime", s.ThrottledTime)
+	printStringStat(w, "Throttled", common.FormatThrottlingRate(s.Throttling))
 }
 
 func printCpuStat(w io.Writer, name string, value float64) {
 	fmt.Fprintf(w, "\t%s:%s%.2f%%\n", name, getTabs(name), value)
 }
 
+func printStringStat(w io.Writer, name string, value string) {
+	fmt.Fprintf(w, "\t%s:%s%s\n", name, getTabs(name), value)
+}
+
+func printRates(w io.Writer, s *CgroupStats) {
+	if len(s.Rates) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Rates")
+	for _, name := range common.SortedRateNames(s.Rates) {
+		fmt.Fprintf(w, "\t%s:\t%s\n", name, common.FormatRate(s.Rates[name]))
+	}
+}
+
 func printBlkIOStats(w io.Writer, s *CgroupStats) {
 	printBlkIOStat(w, "IoWaitTime", s.IoWaitTimeRecursive)
 	printBlkIOStat(w, "IoTimeRecursive", s.IoTimeRecursive)
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	CPUCgroupDir     = "/sys/fs/cgroup/cpu"
+	CPUSetCgroupDir  = "/sys/fs/cgroup/cpuset"
+	CFSQuotaFile     = "cpu.cfs_quota_us"
+	CFSPeriodFile    = "cpu.cfs_period_us"
+	EffectiveCPUFile = "cpuset.effective_cpus"
+)
+
+// readCPULimit reads the CFS quota of the cgroup and each of its ancestors from the cpu hierarchy, and the CPUs on
+// which the cgroup may run from the closest cpuset.effective_cpus of the cpuset hierarchy.
+func readCPULimit(cpuRootDir string, cpusetRootDir string, cgroupPath string) *common.CPULimit {
+	var quotas []common.CPUQuota
+	for _, dir := range common.CgroupDirs(cpuRootDir, cgroupPath) {
+		if quota, err := readCFSQuota(dir); err == nil {
+			quotas = append(quotas, quota)
+		}
+	}
+
+	numCPUs := 0
+	for _, dir := range common.CgroupDirs(cpusetRootDir, cgroupPath) {
+		if cpus, err := os.ReadFile(filepath.Join(dir, EffectiveCPUFile)); err == nil {
+			numCPUs, _ = common.ParseCPUList(string(cpus))
+			break
+		}
+	}
+	return common.NewCPULimit(quotas, numCPUs)
+}
+
+// readCFSQuota reads cpu.cfs_quota_us, which is -1 if there is no quota, and cpu.cfs_period_us.
+func readCFSQuota(cgroupDir string) (common.CPUQuota, error) {
+	quota, err := readInt(filepath.Join(cgroupDir, CFSQuotaFile))
+	if err != nil {
+		return common.CPUQuota{}, err
+	}
+	period, err := readInt(filepath.Join(cgroupDir, CFSPeriodFile))
+	if err != nil {
+		return common.CPUQuota{}, err
+	}
+	if quota < 0 {
+		quota = 0
+	}
+	return common.CPUQuota{QuotaUsec: uint64(quota), PeriodUsec: uint64(period)}, nil
+}
+
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCPULimit(t *testing.T) {
+	// Given
+	cpuRootDir := t.TempDir()
+	cpusetRootDir := t.TempDir()
+	parentDir := filepath.Join(cpuRootDir, "test.slice")
+	cgroupDir := filepath.Join(parentDir, "a.service")
+	assert.NoError(t, os.MkdirAll(cgroupDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(parentDir, CFSQuotaFile), []byte("150000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(parentDir, CFSPeriodFile), []byte("100000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, CFSQuotaFile), []byte("-1\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, CFSPeriodFile), []byte("100000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(cpusetRootDir, EffectiveCPUFile), []byte("0-3\n"), 0644))
+
+	// When
+	limit := readCPULimit(cpuRootDir, cpusetRootDir, "/test.slice/a.service")
+
+	// Then
+	assert.Equal(t, uint64(150000), limit.QuotaUsec, "A quota of -1 should not limit the cgroup.")
+	assert.Equal(t, uint64(100000), limit.PeriodUsec)
+	assert.Equal(t, 4, limit.NumCPUs)
+	assert.Equal(t, 1.5, limit.Cores)
+}
+
+func TestReadCFSQuota(t *testing.T) {
+	// Given
+	cgroupDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, CFSQuotaFile), []byte("-1\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, CFSPeriodFile), []byte("100000\n"), 0644))
+
+	// When
+	quota, err := readCFSQuota(cgroupDir)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), quota.QuotaUsec, "A quota of -1 means there is no quota.")
+	assert.Equal(t, uint64(100000), quota.PeriodUsec)
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"sort"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	nsecPerSecond = 1e9
+	nsecPerUsec   = 1e3
+)
+
+func toMetrics(c *CgroupStats) *common.CgroupMetrics {
+	metrics := []*common.Metric{
+		// cpuacct.usage is reported in nanoseconds.
+		common.NewCounter("cpu_usage_seconds_total", "Total CPU time consumed by the cgroup.",
+			float64(c.CPUUsage)/nsecPerSecond),
+		common.NewCounter("cpu_periods_total", "Number of periods in which the cgroup was runnable.",
+			float64(c.TotalPeriods)),
+		common.NewCounter("cpu_throttled_periods_total", "Number of periods in which the cgroup was throttled.",
+			float64(c.ThrottlePeriods)),
+		common.NewCounter("cpu_throttled_seconds_total", "Total time the cgroup was throttled.",
+			float64(c.ThrottledTime)/nsecPerSecond),
+		common.NewGauge("pids_current", "Number of processes in the cgroup.", float64(c.NumProcesses)),
+		common.NewGauge("memory_usage_bytes", "Memory used by the cgroup.", float64(c.CurrentUsage)),
+		common.NewGauge("memory_max_usage_bytes", "Maximum memory used by the cgroup.", float64(c.MaxUsage)),
+		common.NewGauge("memory_limit_bytes", "Memory limit of the cgroup.", float64(c.UsageLimit)),
+		common.NewGauge("memory_rss_bytes", "Anonymous and swap cache memory used by the cgroup.", float64(c.Rss)),
+		common.NewGauge("memory_cache_bytes", "Page cache memory used by the cgroup.", float64(c.CacheSize)),
+		common.NewGauge("memory_dirty_bytes", "Memory waiting to be written back to disk.", float64(c.DirtySize)),
+		common.NewGauge("memory_writeback_bytes", "Memory actively being written back to disk.", float64(c.WriteBack)),
+		common.NewCounter("memory_page_faults_total", "Number of page faults incurred by the cgroup.",
+			float64(c.PgFault)),
+		common.NewCounter("memory_major_page_faults_total", "Number of major page faults incurred by the cgroup.",
+			float64(c.PgMajFault)),
+		common.NewCounter("memory_oom_kill_events_total", "Number of processes in the cgroup killed by the OOM killer.",
+			float64(c.OomKill)),
+		common.NewGauge("memory_under_oom", "Whether the cgroup is under OOM.", float64(c.UnderOom)),
+	}
+
+	if c.CPULimit != nil {
+		metrics = append(metrics, common.NewGauge("cpu_limit_cores",
+			"CPU bandwidth available to the cgroup, from its quota and cpuset.", c.CPULimit.Cores))
+	}
+
+	metrics = append(metrics, toBlockDeviceMetrics(c.IoServiceBytesRecursive, "io_read_bytes_total",
+		"Number of bytes read from the device.", "io_write_bytes_total", "Number of bytes written to the device.")...)
+	metrics = append(metrics, toBlockDeviceMetrics(c.IoServicedRecursive, "io_reads_total",
+		"Number of read IOs issued to the device.", "io_writes_total", "Number of write IOs issued to the device.")...)
+
+	return &common.CgroupMetrics{
+		Name:    c.Name,
+		Metrics: metrics,
+	}
+}
+
+func toBlockDeviceMetrics(devices map[string]*BlockDevice, readName string, readHelp string, writeName string,
+	writeHelp string) []*common.Metric {
+	deviceNames := make([]string, 0, len(devices))
+	for deviceName := range devices {
+		deviceNames = append(deviceNames, deviceName)
+	}
+	sort.Strings(deviceNames)
+
+	var metrics []*common.Metric
+	for _, deviceName := range deviceNames {
+		device := devices[deviceName]
+		metrics = append(metrics,
+			common.NewCounter(readName, readHelp, float64(device.Read)).WithLabel("device", deviceName),
+			common.NewCounter(writeName, writeHelp, float64(device.Write)).WithLabel("device", deviceName),
+		)
+	}
+	return metrics
+}
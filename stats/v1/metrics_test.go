@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMetrics_PageFaults(t *testing.T) {
+	// Given
+	cgroupStats := &CgroupStats{Name: "/a.service", PgFault: 300, PgMajFault: 4}
+
+	// When
+	cgroupMetrics := toMetrics(cgroupStats)
+
+	// Then
+	values := map[string]float64{}
+	for _, metric := range cgroupMetrics.Metrics {
+		values[metric.Name] = metric.Value
+	}
+	assert.Equal(t, 300.0, values["memory_page_faults_total"], "Page faults should be exported like on cgroup v2.")
+	assert.Equal(t, 4.0, values["memory_major_page_faults_total"])
+}
@@ -1,5 +1,7 @@
 package v1
 
+import "github.com/strategicpause/cgstat/stats/common"
+
 type Cgroup struct {
 	Name string
 }
@@ -19,12 +21,18 @@ type CgroupStats struct {
 	CPUUtilization float64
 	// System time in microseconds
 	SystemTime int64
-	// CPU Usage in microseconds
+	// CPU Usage in nanoseconds
 	CPUUsage uint64
 	// The total CPU throttled time
 	ThrottlePeriods uint64
 	//
 	TotalPeriods uint64
+	// The total time the cgroup was throttled, in nanoseconds.
+	ThrottledTime uint64
+	// Throttling since the previous sample, which is nil for the first sample of a cgroup.
+	Throttling *common.ThrottlingRate
+	// The CPU bandwidth available to the cgroup.
+	CPULimit *common.CPULimit
 	// The number of processes currently in the cgroup and its descendants.
 	NumProcesses uint64
 	// Hard limit of number of processes.
@@ -50,13 +58,14 @@ type CgroupStats struct {
 	DirtySize uint64
 	// The total amount of memory actively being written back to the disk.
 	WriteBack uint64
-	// Number of bytes the system has paged in from disk per second.
+	// Total number of pages charged to the cgroup, such as when a page is read from disk or an anonymous page is
+	// allocated.
 	PgPgIn uint64
-	// Number of bytes the system has paged out to disk per second.
+	// Total number of pages uncharged from the cgroup.
 	PgPgOut uint64
-	// Number of page faults the system has made per second.
+	// Total number of page faults incurred by the cgroup.
 	PgFault uint64
-	// Number of major faults per second the system required loading a memory page from disk.
+	// Total number of major page faults, which required loading a memory page from disk.
 	PgMajFault uint64
 	// The amount of anonymous and tmpfs/shmem memory, that is in active use, or was in active use since
 	// the last time the system moved something to swap.
@@ -76,6 +85,8 @@ type CgroupStats struct {
 	// The cgroup is under OOM, tasks may be stopped.
 	UnderOom uint64
 	/** IO Stats **/
+	// Each of the IO stats is keyed by the block device name (such as nvme0n1p2), or by its major:minor device
+	// number if the name could not be resolved.
 	// The total amount of time the IOs for this cgroup spent waiting in the scheduler queues for service.
 	IoWaitTimeRecursive map[string]*BlockDevice
 	// The disk time allocated to cgroup per device in milliseconds.
@@ -92,4 +103,8 @@ type CgroupStats struct {
 	SectorsRecursive map[string]*BlockDevice
 	// The number of IOs (bio) issued to the disk by the group.
 	IoServicedRecursive map[string]*BlockDevice
+	// ID is the inode of the cgroup directory, which changes when the cgroup is removed and recreated.
+	ID uint64
+	// Rates contains the per-second rate of each counter since the previous sample, keyed by the name of the counter.
+	Rates map[string]float64
 }
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"errors"
+	"path/filepath"
+
 	cgroups "github.com/containerd/cgroups/v3/cgroup1"
 	v1 "github.com/containerd/cgroups/v3/cgroup1/stats"
 	"github.com/strategicpause/cgstat/stats/common"
@@ -8,24 +11,32 @@ import (
 )
 
 type CgroupStatsProvider struct {
-	commonProvider               *common.CommonCgroupStatsProvider
-	previousCPUStatsByCgroupPath map[string]*CgroupStats
+	config         *common.ProviderConfig
+	commonProvider *common.CommonCgroupStatsProvider
+	deviceResolver *common.DeviceResolver
+	counters       *common.CounterStore
 }
 
 const (
 	CgroupPrefix = "/sys/fs/cgroup/pids"
 )
 
-func NewCgroupStatsProvider() *CgroupStatsProvider {
+func NewCgroupStatsProvider(opts ...common.ProviderOpt) *CgroupStatsProvider {
 	return &CgroupStatsProvider{
-		commonProvider:               common.NewCommonCgroupStatsProvider(CgroupPrefix),
-		previousCPUStatsByCgroupPath: map[string]*CgroupStats{},
+		config:         common.NewProviderConfig(opts...),
+		deviceResolver: common.NewDeviceResolver(),
+		commonProvider: common.NewCommonCgroupStatsProvider(CgroupPrefix),
+		counters:       common.NewCounterStore(),
 	}
 }
 
 func (c *CgroupStatsProvider) GetCgroupStatsByPrefix(prefix string) (common.CgroupStatsCollection, error) {
 	paths := c.ListCgroupsByPrefix(prefix)
-	return c.getCgroupStatsByPath(paths)
+	collection, err := c.getCgroupStatsByPath(paths)
+	// Samples of a single cgroup by name, such as those of the detail view of the interactive view, keep the counters
+	// of the other cgroups.
+	c.counters.Retain(paths)
+	return collection, err
 }
 
 func (c *CgroupStatsProvider) ListCgroupsByPrefix(cgroupPrefix string) []string {
@@ -38,23 +49,39 @@ func (c *CgroupStatsProvider) GetCgroupStatsByName(name string) (common.CgroupSt
 	return c.getCgroupStatsByPath(paths)
 }
 
+func (c *CgroupStatsProvider) SetPreviousSample(collection common.CgroupStatsCollection) error {
+	previous, ok := collection.(common.Collection[*CgroupStats])
+	if !ok {
+		return errors.New("previous sample is not a cgroup v1 sample")
+	}
+	for _, cgStats := range previous.Stats {
+		c.counters.Set(cgStats.Name, cgStats.counterSample())
+	}
+	return nil
+}
+
 func (c *CgroupStatsProvider) getCgroupStatsByPath(cgroupPaths []string) (common.CgroupStatsCollection, error) {
-	var stats []*CgroupStats
-	for _, cgroupPath := range cgroupPaths {
-		control, err := cgroups.Load(cgroups.StaticPath(cgroupPath))
-		if err != nil {
-			return nil, err
-		}
-		cgroupStats, err := c.getCgroupStats(cgroupPath, control)
+	// Every cgroup of a sample shares the same timestamp, so that rates cover the same interval.
+	sampleTime := time.Now()
+	stats, errs := common.CollectConcurrently(cgroupPaths, c.config.Concurrency,
+		func(cgroupPath string) (*CgroupStats, error) {
+			control, err := cgroups.Load(cgroups.StaticPath(cgroupPath))
+			if err != nil {
+				return nil, err
+			}
+			return c.getCgroupStats(cgroupPath, control, sampleTime)
+		})
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, cgroupStats)
 	}
-	return NewCollection(stats), nil
+	collection := newCollection(stats)
+	collection.Timestamp = sampleTime
+	return collection, nil
 }
 
-func (c *CgroupStatsProvider) getCgroupStats(name string, control cgroups.Cgroup) (*CgroupStats, error) {
+func (c *CgroupStatsProvider) getCgroupStats(name string, control cgroups.Cgroup, sampleTime time.Time) (*CgroupStats, error) {
 	metrics, err := control.Stat(cgroups.IgnoreNotExist)
 
 	if err != nil {
@@ -62,21 +89,21 @@ func (c *CgroupStatsProvider) getCgroupStats(name string, control cgroups.Cgroup
 	}
 	cgStats := &CgroupStats{
 		Name: name,
+		ID:   common.CgroupID(filepath.Join(CgroupPrefix, name)),
 	}
 	processes, err := control.Processes("cpu", true)
 	if err != nil {
 		return nil, err
 	}
 
-	prevStats := c.previousCPUStatsByCgroupPath[name]
-
 	c.withProcessStats(cgStats, processes)
-	c.withCpuStats(cgStats, metrics.CPU, prevStats)
+	c.withPidStats(cgStats, metrics.Pids)
+	c.withCpuStats(cgStats, metrics.CPU, sampleTime)
 	c.withMemoryOomControl(cgStats, metrics.MemoryOomControl)
 	c.withMemoryStats(cgStats, metrics.Memory)
 	c.withIOStats(cgStats, metrics.Blkio)
 
-	c.previousCPUStatsByCgroupPath[name] = cgStats
+	cgStats.withRates(c.counters.Update(name, cgStats.counterSample()))
 
 	return cgStats, nil
 }
@@ -85,19 +112,20 @@ func (c *CgroupStatsProvider) withProcessStats(cgStats *CgroupStats, processes [
 	cgStats.NumProcesses = uint64(len(processes))
 }
 
-func (c *CgroupStatsProvider) withCpuStats(cgStats *CgroupStats, cpuMetrics *v1.CPUStat, prevStats *CgroupStats) {
-	cgStats.SystemTime = time.Now().UnixMicro()
+func (c *CgroupStatsProvider) withPidStats(cgStats *CgroupStats, pidMetrics *v1.PidsStat) {
+	if pidMetrics == nil {
+		return
+	}
+	cgStats.MaxProcesses = pidMetrics.Limit
+}
+
+func (c *CgroupStatsProvider) withCpuStats(cgStats *CgroupStats, cpuMetrics *v1.CPUStat, sampleTime time.Time) {
+	cgStats.SystemTime = sampleTime.UnixMicro()
 	cgStats.CPUUsage = cpuMetrics.GetUsage().Total
 	cgStats.ThrottlePeriods = cpuMetrics.Throttling.ThrottledPeriods
 	cgStats.TotalPeriods = cpuMetrics.Throttling.Periods
-
-	if prevStats == nil {
-		cgStats.CPUUtilization = 0.0
-	} else {
-		cpuUsageDelta := float64(cgStats.CPUUsage - prevStats.CPUUsage)
-		systemTimeDelta := float64(cgStats.SystemTime - prevStats.SystemTime)
-		cgStats.CPUUtilization = (cpuUsageDelta / systemTimeDelta) * 100.0
-	}
+	cgStats.ThrottledTime = cpuMetrics.Throttling.ThrottledTime
+	cgStats.CPULimit = readCPULimit(CPUCgroupDir, CPUSetCgroupDir, cgStats.Name)
 }
 
 func (c *CgroupStatsProvider) withMemoryOomControl(cgStats *CgroupStats, oomMetrics *v1.MemoryOomControl) {
@@ -121,6 +149,7 @@ func (c *CgroupStatsProvider) withMemoryStats(cgStats *CgroupStats, memMetrics *
 	cgStats.Rss = memMetrics.RSS
 	cgStats.PgPgIn = memMetrics.PgPgIn
 	cgStats.PgPgOut = memMetrics.TotalPgPgOut
+	cgStats.PgFault = memMetrics.PgFault
 	cgStats.PgMajFault = memMetrics.PgMajFault
 	cgStats.ActiveAnon = memMetrics.ActiveAnon
 	cgStats.InactiveAnon = memMetrics.InactiveAnon
@@ -136,7 +165,7 @@ func (c *CgroupStatsProvider) withIOStats(cgStats *CgroupStats, ioMetrics *v1.Bl
 	if ioMetrics == nil {
 		return
 	}
-	cgStats.IoServicedRecursive = c.getBlockDeviceStats(ioMetrics.IoServiceTimeRecursive)
+	cgStats.IoServicedRecursive = c.getBlockDeviceStats(ioMetrics.IoServicedRecursive)
 	cgStats.IoServiceBytesRecursive = c.getBlockDeviceStats(ioMetrics.IoServiceBytesRecursive)
 	cgStats.IoQueuedRecursive = c.getBlockDeviceStats(ioMetrics.IoQueuedRecursive)
 	cgStats.IoTimeRecursive = c.getBlockDeviceStats(ioMetrics.IoTimeRecursive)
@@ -149,7 +178,10 @@ func (c *CgroupStatsProvider) withIOStats(cgStats *CgroupStats, ioMetrics *v1.Bl
 func (c *CgroupStatsProvider) getBlockDeviceStats(entries []*v1.BlkIOEntry) map[string]*BlockDevice {
 	devices := make(map[string]*BlockDevice)
 	for _, entry := range entries {
-		deviceName := entry.Device
+		deviceName := c.deviceResolver.Resolve(entry.Major, entry.Minor)
+		if !c.config.IncludesDevice(entry.Major, entry.Minor, deviceName) {
+			continue
+		}
 		device, ok := devices[deviceName]
 		if !ok {
 			device = &BlockDevice{}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRates(t *testing.T) {
+	// Given
+	store := common.NewCounterStore()
+	previous := &CgroupStats{ID: 1, SystemTime: 1_000_000, CPUUsage: 1_000_000_000, ThrottlePeriods: 10,
+		TotalPeriods: 100, ThrottledTime: 0, PgFault: 100}
+	store.Update("/a.service", previous.counterSample())
+
+	t.Run("Convert CPU usage and throttled time from nanoseconds.", func(t *testing.T) {
+		cgroupStats := &CgroupStats{ID: 1, SystemTime: 2_000_000, CPUUsage: 1_500_000_000, ThrottlePeriods: 15,
+			TotalPeriods: 120, ThrottledTime: 250_000_000, PgFault: 300}
+
+		cgroupStats.withRates(store.Update("/a.service", cgroupStats.counterSample()))
+
+		assert.Equal(t, 50.0, cgroupStats.CPUUtilization)
+		assert.Equal(t, uint64(5), cgroupStats.Throttling.ThrottledPeriods)
+		assert.Equal(t, uint64(20), cgroupStats.Throttling.RunnablePeriods)
+		assert.Equal(t, 250_000.0, cgroupStats.Throttling.ThrottledUsecPerSec)
+		assert.Equal(t, 200.0, cgroupStats.Rates[common.PageFaultsCounter])
+	})
+
+	t.Run("Ignore a previous sample of a recreated cgroup.", func(t *testing.T) {
+		cgroupStats := &CgroupStats{ID: 2, SystemTime: 3_000_000, CPUUsage: 2_000_000_000}
+
+		cgroupStats.withRates(store.Update("/a.service", cgroupStats.counterSample()))
+
+		assert.Equal(t, 0.0, cgroupStats.CPUUtilization)
+		assert.Nil(t, cgroupStats.Throttling)
+		assert.Nil(t, cgroupStats.Rates)
+	})
+}
+
+func TestToSnapshot(t *testing.T) {
+	// Given
+	cgroupStats := &CgroupStats{Name: "/a.service", CPUUsage: 1_500_000_000, ThrottledTime: 250_000_000}
+
+	// When
+	snapshot := toSnapshot(cgroupStats)
+
+	// Then
+	assert.Equal(t, uint64(1_500_000), snapshot.CPU.UsageUsec)
+	assert.Equal(t, uint64(250_000), *snapshot.CPU.ThrottledUsec)
+}
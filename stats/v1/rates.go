@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// counterSample returns every monotonically increasing counter of the cgroup.
+func (c *CgroupStats) counterSample() *common.CounterSample {
+	sample := common.NewCounterSample(c.SystemTime, c.ID)
+	// cpuacct.usage is reported in nanoseconds.
+	sample.Counters[common.CPUUsageCounter] = c.CPUUsage / nsecPerUsec
+	sample.Counters[common.ThrottledPeriodsCounter] = c.ThrottlePeriods
+	sample.Counters[common.RunnablePeriodsCounter] = c.TotalPeriods
+	sample.Counters[common.ThrottledUsecCounter] = c.ThrottledTime / nsecPerUsec
+	sample.Counters[common.PageFaultsCounter] = c.PgFault
+	sample.Counters[common.MajorPageFaultsCounter] = c.PgMajFault
+	sample.Counters["memory.pgpgin"] = c.PgPgIn
+	sample.Counters["memory.pgpgout"] = c.PgPgOut
+	sample.Counters[common.OomKillsCounter] = c.OomKill
+	for deviceName, device := range c.IoServiceBytesRecursive {
+		sample.Counters[ioCounterName(deviceName, "read_bytes")] = device.Read
+		sample.Counters[ioCounterName(deviceName, "write_bytes")] = device.Write
+	}
+	for deviceName, device := range c.IoServicedRecursive {
+		sample.Counters[ioCounterName(deviceName, "read_ios")] = device.Read
+		sample.Counters[ioCounterName(deviceName, "write_ios")] = device.Write
+	}
+	return sample
+}
+
+func ioCounterName(deviceName string, counter string) string {
+	return fmt.Sprintf("io.%s.%s", deviceName, counter)
+}
+
+// withRates sets the stats which are calculated from the increase of the counters since the previous sample, which
+// is nil if there was none.
+func (c *CgroupStats) withRates(deltas *common.CounterDeltas) {
+	c.CPUUtilization = common.UtilizationFromDeltas(deltas)
+	c.Throttling = common.NewThrottlingRate(deltas)
+	c.Rates = deltas.Rates()
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+func toSnapshot(c *CgroupStats) *common.CgroupSnapshot {
+	snapshot := &common.CgroupSnapshot{
+		Name:      c.Name,
+		Timestamp: time.UnixMicro(c.SystemTime),
+		Rates:     c.Rates,
+		CPU: &common.CPUSnapshot{
+			// cpuacct.usage is reported in nanoseconds.
+			UsageUsec:        c.CPUUsage / nsecPerUsec,
+			Utilization:      c.CPUUtilization,
+			ThrottledPeriods: c.ThrottlePeriods,
+			TotalPeriods:     c.TotalPeriods,
+			ThrottledUsec:    common.Uint64(c.ThrottledTime / nsecPerUsec),
+			Limit:            c.CPULimit,
+			Throttling:       c.Throttling,
+		},
+		Memory: &common.MemorySnapshot{
+			Usage:           c.CurrentUsage,
+			Limit:           c.UsageLimit,
+			MaxUsage:        common.Uint64(c.MaxUsage),
+			Anon:            c.Rss,
+			File:            c.CacheSize,
+			Kernel:          c.KernelUsage,
+			Dirty:           c.DirtySize,
+			Writeback:       c.WriteBack,
+			PageFaults:      c.PgFault,
+			MajorPageFaults: c.PgMajFault,
+		},
+		PIDs: &common.PIDSnapshot{
+			Current: c.NumProcesses,
+			Limit:   c.MaxProcesses,
+		},
+		Events: &common.EventSnapshot{
+			OomKills: c.OomKill,
+			UnderOom: common.Bool(c.UnderOom != 0),
+		},
+	}
+	if len(c.IoServiceBytesRecursive) > 0 || len(c.IoServicedRecursive) > 0 {
+		snapshot.IO = &common.IOSnapshot{
+			Devices: map[string]*common.IODeviceSnapshot{},
+		}
+		for deviceName, device := range c.IoServiceBytesRecursive {
+			ioDevice := getOrCreateIODevice(snapshot.IO.Devices, deviceName)
+			ioDevice.ReadBytes = device.Read
+			ioDevice.WriteBytes = device.Write
+		}
+		for deviceName, device := range c.IoServicedRecursive {
+			ioDevice := getOrCreateIODevice(snapshot.IO.Devices, deviceName)
+			ioDevice.ReadIOs = device.Read
+			ioDevice.WriteIOs = device.Write
+		}
+	}
+	return snapshot
+}
+
+func getOrCreateIODevice(devices map[string]*common.IODeviceSnapshot, deviceName string) *common.IODeviceSnapshot {
+	device, ok := devices[deviceName]
+	if !ok {
+		device = &common.IODeviceSnapshot{}
+		devices[deviceName] = device
+	}
+	return device
+}
@@ -1,8 +1,11 @@
 package v2
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/rodaine/table"
@@ -10,26 +13,62 @@ import (
 )
 
 func NewCollection(stats []*CgroupStats) common.CgroupStatsCollection {
+	return newCollection(stats)
+}
+
+// DecodeCollection will decode the JSON encoded Cgroups of a JSONOutput, which were collected at the given time.
+func DecodeCollection(timestamp time.Time, data []byte) (common.CgroupStatsCollection, error) {
+	var stats []*CgroupStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("could not decode v2 cgroup stats: %w", err)
+	}
+	collection := newCollection(stats)
+	collection.Timestamp = timestamp
+	return collection, nil
+}
+
+func newCollection(stats []*CgroupStats) common.Collection[*CgroupStats] {
 	return common.Collection[*CgroupStats]{
+		CgroupVersion:            common.CgroupV2,
 		Stats:                    stats,
 		CsvHeadersProvider:       getCSVHeaders,
 		CsvRowTransformer:        toCSVRow,
 		DisplayHeadersProvider:   getDisplayHeaders,
 		DisplayRowTransformer:    toDisplayRow,
 		VerboseOutputTransformer: toVerboseOutput,
+		MetricsTransformer:       toMetrics,
+		SnapshotTransformer:      toSnapshot,
 	}
 }
 
 func getCSVHeaders() []string {
-	return []string{
-		"Name", "Timestamp", "Throttled Periods", "Runnable Periods", "Current PIDs", "PID Limit", "Anon Memory Usage",
-		"Kernel Memory", "Page Cache", "OOM Events", "OOM Kill Events", "TCP Sockets", "UDP Sockets", "Open Files",
+	headers := []string{
+		"Timestamp", "Name", "CPU Usage", "Throttled Periods", "Runnable Periods", "Current PIDs", "PID Limit",
+		"Anon Memory Usage", "Kernel Memory", "Page Cache", "OOM Events", "OOM Kill Events", "TCP Sockets",
+		"UDP Sockets", "Open Files", "IO Read Bytes", "IO Write Bytes", "IO Read IOs", "IO Write IOs",
+		"IO Discard Bytes", "IO Discard IOs", "IO Read Bytes/s", "IO Write Bytes/s", "IO Read IOPS", "IO Write IOPS",
+		"CPU Cores", "CPU Limit Cores", "CPU Limit Usage", "Throttled Percent", "Throttled Periods/s",
+		"Throttled Usec/s", "Page Faults/s", "Major Page Faults/s", "Network Namespaces", "Host Network Namespace",
+		"Net RX Bytes", "Net TX Bytes", "Net RX Bytes/s", "Net TX Bytes/s", "Open Regular Files", "Open Sockets",
+		"Open Pipes", "Open Anon Inodes", "Open Other FDs", "FD Limit Usage", "FD Limit PID",
+		"Unknown Network Namespace Processes",
 	}
+	for _, resource := range pressureResourceNames {
+		for _, kind := range []string{"Some", "Full"} {
+			headers = append(headers,
+				fmt.Sprintf("%s Pressure %s Avg10", resource, kind),
+				fmt.Sprintf("%s Pressure %s Avg60", resource, kind),
+				fmt.Sprintf("%s Pressure %s Avg300", resource, kind),
+				fmt.Sprintf("%s Pressure %s Total", resource, kind),
+			)
+		}
+	}
+	return headers
 }
 
 func toCSVRow(c *CgroupStats) []string {
-	t, _ := time.Now().UTC().MarshalText()
-	return []string{
+	t, _ := time.UnixMicro(c.CPU.SystemTime).UTC().MarshalText()
+	row := []string{
 		string(t),
 		c.Name,
 		fmt.Sprintf("%f", c.CPU.Utilization),
@@ -46,33 +85,110 @@ func toCSVRow(c *CgroupStats) []string {
 		fmt.Sprintf("%d", c.Network.UDPStats.Sockets),
 		fmt.Sprintf("%d", c.ProcStats.NumFD),
 	}
+	io := c.IO.Total()
+	row = append(row,
+		fmt.Sprintf("%d", io.ReadBytes),
+		fmt.Sprintf("%d", io.WriteBytes),
+		fmt.Sprintf("%d", io.ReadIOs),
+		fmt.Sprintf("%d", io.WriteIOs),
+		fmt.Sprintf("%d", io.DiscardBytes),
+		fmt.Sprintf("%d", io.DiscardIOs),
+		fmt.Sprintf("%f", io.ReadBytesPerSec),
+		fmt.Sprintf("%f", io.WriteBytesPerSec),
+		fmt.Sprintf("%f", io.ReadIOPS),
+		fmt.Sprintf("%f", io.WriteIOPS),
+		fmt.Sprintf("%f", c.CPU.Utilization/100.0),
+		fmt.Sprintf("%f", c.CPU.Limit.GetCores()),
+		fmt.Sprintf("%f", c.CPU.Limit.UsagePercent(c.CPU.Utilization)),
+	)
+	row = append(row, common.ThrottlingRateCSVColumns(c.CPU.Throttling)...)
+	row = append(row,
+		fmt.Sprintf("%f", c.Rates[common.PageFaultsCounter]),
+		fmt.Sprintf("%f", c.Rates[common.MajorPageFaultsCounter]),
+		fmt.Sprintf("%d", len(c.Network.Namespaces)),
+		fmt.Sprintf("%t", c.Network.SharesHostNamespace),
+	)
+	netTotal := c.Network.InterfaceTotal()
+	row = append(row,
+		fmt.Sprintf("%d", netTotal.RxBytes),
+		fmt.Sprintf("%d", netTotal.TxBytes),
+		fmt.Sprintf("%f", netTotal.RxBytesPerSec),
+		fmt.Sprintf("%f", netTotal.TxBytesPerSec),
+	)
+	fdLimitPID := 0
+	if c.ProcStats.ClosestToFDLimit != nil {
+		fdLimitPID = c.ProcStats.ClosestToFDLimit.PID
+	}
+	row = append(row,
+		fmt.Sprintf("%d", c.ProcStats.FDs.Files),
+		fmt.Sprintf("%d", c.ProcStats.FDs.Sockets),
+		fmt.Sprintf("%d", c.ProcStats.FDs.Pipes),
+		fmt.Sprintf("%d", c.ProcStats.FDs.AnonInodes),
+		fmt.Sprintf("%d", c.ProcStats.FDs.Other),
+		fmt.Sprintf("%f", c.ProcStats.ClosestToFDLimit.UsagePercent()),
+		fmt.Sprintf("%d", fdLimitPID),
+		fmt.Sprintf("%d", c.Network.UnknownNamespaceProcesses()),
+	)
+	for _, pressure := range c.Pressure.byResource() {
+		row = append(row, toPressureCSVColumns(pressure.getSome())...)
+		row = append(row, toPressureCSVColumns(pressure.getFull())...)
+	}
+	return row
+}
+
+func toPressureCSVColumns(p *PressureData) []string {
+	if p == nil {
+		return []string{"", "", "", ""}
+	}
+	return []string{
+		fmt.Sprintf("%f", p.Avg10),
+		fmt.Sprintf("%f", p.Avg60),
+		fmt.Sprintf("%f", p.Avg300),
+		fmt.Sprintf("%d", p.Total),
+	}
 }
 
 func getDisplayHeaders() []interface{} {
 	return []interface{}{
-		"Name", "CPU Usage", "Throttled Periods", "PIDs", "Mem Usage", "Anon Mem", "Swap Mem", "File Mem", "Kernel Mem",
-		"OOM Events / Kills", "TCP Sockets", "UDP Sockets", "Open Files",
+		"Name", "CPU Usage", "CPU Cores / Limit", "Throttled (Interval)", "PIDs", "Mem Usage", "Anon Mem", "Swap Mem", "File Mem", "Kernel Mem",
+		"Page Faults/s (Major)", "OOM Events / Kills", "TCP Sockets", "UDP Sockets", "Net I/O (RX / TX)", "Open Files", "IO Read / Write",
+		"IOPS Read / Write", "Pressure (CPU/Mem/IO)",
 	}
 }
 
 func toDisplayRow(c *CgroupStats) []interface{} {
 	cgroupName := common.Shorten(c.Name, 32)
 	cpuUsage := fmt.Sprintf("%.2f%%", c.CPU.Utilization)
-	throttledPeriods := common.DisplayRatio(c.CPU.NumThrottledPeriods, c.CPU.NumRunnablePeriods)
+	cpuCores := common.FormatCPUCores(c.CPU.Utilization, c.CPU.Limit)
+	throttledPeriods := common.FormatThrottlingRate(c.CPU.Throttling)
 	pids := common.DisplayRatio(c.PID.Current, c.PID.Limit)
 	memUsage := common.DisplayRatio(c.Memory.Usage-c.Memory.Filesystem.Inactive, c.Memory.UsageLimit, common.WithBytes())
 	anonMemUsage := common.FormatBytes(c.Memory.Anon.Total)
 	swapMemUsage := common.FormatBytes(c.Memory.Swap.Usage)
 	fileMemUsage := common.FormatBytes(c.Memory.Filesystem.Active + c.Memory.Filesystem.Inactive)
 	kernelMemUsage := common.FormatBytes(c.Memory.Kernel.Slab + c.Memory.Kernel.Stack)
+	pageFaults := fmt.Sprintf("%s (%s)", common.FormatRate(c.Rates[common.PageFaultsCounter]),
+		common.FormatRate(c.Rates[common.MajorPageFaultsCounter]))
 	numOomEvents := fmt.Sprintf("%d / %d", c.MemoryEvent.NumOomEvents, c.MemoryEvent.NumOomKillEvents)
-	tcpSockets := fmt.Sprintf("%d (%s)", c.Network.TCPStats.Sockets, common.FormatBytes(c.Network.TCPStats.SocketMemory))
-	udpSockets := fmt.Sprintf("%d (%s)", c.Network.UDPStats.Sockets, common.FormatBytes(c.Network.UDPStats.SocketMemory))
-	numFDs := fmt.Sprintf("%d", c.ProcStats.NumFD)
+	tcpSockets := formatSockets(c.Network.TCPStats.Sockets, c.Network.TCPStats.SocketMemory, c.Network)
+	udpSockets := formatSockets(c.Network.UDPStats.Sockets, c.Network.UDPStats.SocketMemory, c.Network)
+	netTotal := c.Network.InterfaceTotal()
+	netIO := fmt.Sprintf("%s/s / %s/s", common.FormatBytes(uint64(netTotal.RxBytesPerSec)),
+		common.FormatBytes(uint64(netTotal.TxBytesPerSec)))
+	numFDs := common.FormatOpenFiles(c.ProcStats.NumFD, 0, 0)
+	if closest := c.ProcStats.ClosestToFDLimit; closest != nil {
+		numFDs = common.FormatOpenFiles(c.ProcStats.NumFD, closest.UsagePercent(), closest.PID)
+	}
+	io := c.IO.Total()
+	ioThroughput := fmt.Sprintf("%s/s / %s/s", common.FormatBytes(uint64(io.ReadBytesPerSec)),
+		common.FormatBytes(uint64(io.WriteBytesPerSec)))
+	iops := fmt.Sprintf("%.1f / %.1f", io.ReadIOPS, io.WriteIOPS)
+	pressure := formatPressureSummary(c.Pressure)
 
 	return []interface{}{
 		cgroupName,
 		cpuUsage,
+		cpuCores,
 		throttledPeriods,
 		pids,
 		memUsage,
@@ -80,11 +196,60 @@ func toDisplayRow(c *CgroupStats) []interface{} {
 		swapMemUsage,
 		fileMemUsage,
 		kernelMemUsage,
+		pageFaults,
 		numOomEvents,
 		tcpSockets,
 		udpSockets,
+		netIO,
 		numFDs,
+		ioThroughput,
+		iops,
+		pressure,
+	}
+}
+
+// formatPressureSummary displays the "some" avg10 pressure of CPU, memory and IO, which is the share of time in the
+// last 10 seconds that at least one task in the cgroup was stalled on the given resource.
+func formatPressureSummary(p *PressureStallStats) string {
+	var summary []string
+	for _, pressure := range p.byResource() {
+		if some := pressure.getSome(); some != nil {
+			summary = append(summary, fmt.Sprintf("%.2f%%", some.Avg10))
+		} else {
+			summary = append(summary, "-")
+		}
+	}
+	return strings.Join(summary, " / ")
+}
+
+func formatPressureData(p *PressureData) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%% (10s) %.2f%% (60s) %.2f%% (300s) %dus (Total)", p.Avg10, p.Avg60, p.Avg300, p.Total)
+}
+
+// formatTCPStates displays the number of sockets in each TCP state in the order the kernel numbers them, such as
+// "ESTABLISHED 12, TIME_WAIT 3, CLOSE_WAIT 1", leaving out states without sockets.
+func formatTCPStates(states map[string]uint64) string {
+	var formatted []string
+	for _, state := range sortedTCPStates(states) {
+		formatted = append(formatted, fmt.Sprintf("%s %d", state, states[state]))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatSockets displays the number of sockets and their memory, marking counts which include every socket of the
+// host.
+func formatSockets(sockets uint64, memory uint64, network *NetworkStats) string {
+	formatted := fmt.Sprintf("%d (%s)", sockets, common.FormatBytes(memory))
+	if network.SharesHostNamespace {
+		formatted += common.HostNamespaceMarker
+	}
+	if network.UnknownNamespace != nil {
+		formatted += common.UnknownNamespaceMarker
 	}
+	return formatted
 }
 
 func toVerboseOutput(w io.Writer, c []*CgroupStats) {
@@ -94,10 +259,86 @@ func toVerboseOutput(w io.Writer, c []*CgroupStats) {
 		tbl.AddRow("Name:", cgroupStats.Name)
 		tbl.AddRow("PIDs:", common.DisplayRatio(cgroupStats.PID.Current, cgroupStats.PID.Limit, common.WithTotal()))
 		tbl.AddRow("CPU Usage:", cgroupStats.CPU.Utilization)
+		tbl.AddRow("CPU Cores:", common.FormatCPUCores(cgroupStats.CPU.Utilization, cgroupStats.CPU.Limit))
+		tbl.AddRow("CPU Limit:", cgroupStats.CPU.Limit)
 		tbl.AddRow("Throttled Periods:", common.DisplayRatio(cgroupStats.CPU.NumThrottledPeriods, cgroupStats.CPU.NumRunnablePeriods, common.WithTotal()))
 		tbl.AddRow("Throttled Time:", cgroupStats.CPU.ThrottledTimeInUsec)
+		tbl.AddRow("Throttled (Interval):", common.FormatThrottlingRate(cgroupStats.CPU.Throttling))
 		tbl.AddRow("System Usage", common.DisplayRatio(cgroupStats.CPU.SystemTimeInUsec, cgroupStats.CPU.UsageInUsec, common.WithTotal()))
 		tbl.AddRow("User Usage", common.DisplayRatio(cgroupStats.CPU.UserTimeInUsec, cgroupStats.CPU.UsageInUsec, common.WithTotal()))
+		for i, pressure := range cgroupStats.Pressure.byResource() {
+			tbl.AddRow(fmt.Sprintf("%s Pressure (Some):", pressureResourceNames[i]), formatPressureData(pressure.getSome()))
+			tbl.AddRow(fmt.Sprintf("%s Pressure (Full):", pressureResourceNames[i]), formatPressureData(pressure.getFull()))
+		}
+		if cgroupStats.IO != nil {
+			deviceNames := make([]string, 0, len(cgroupStats.IO.Devices))
+			for deviceName := range cgroupStats.IO.Devices {
+				deviceNames = append(deviceNames, deviceName)
+			}
+			sort.Strings(deviceNames)
+			for _, deviceName := range deviceNames {
+				device := cgroupStats.IO.Devices[deviceName]
+				tbl.AddRow(fmt.Sprintf("IO Bytes (%s):", deviceName), fmt.Sprintf("%s (Read) %s (Write) %s (Discard)",
+					common.FormatBytes(device.ReadBytes), common.FormatBytes(device.WriteBytes),
+					common.FormatBytes(device.DiscardBytes)))
+				tbl.AddRow(fmt.Sprintf("IO Operations (%s):", deviceName), fmt.Sprintf("%d (Read) %d (Write) %d (Discard)",
+					device.ReadIOs, device.WriteIOs, device.DiscardIOs))
+				tbl.AddRow(fmt.Sprintf("IO Throughput (%s):", deviceName), fmt.Sprintf("%s/s (Read) %s/s (Write)",
+					common.FormatBytes(uint64(device.ReadBytesPerSec)), common.FormatBytes(uint64(device.WriteBytesPerSec))))
+				tbl.AddRow(fmt.Sprintf("IOPS (%s):", deviceName), fmt.Sprintf("%.1f (Read) %.1f (Write)",
+					device.ReadIOPS, device.WriteIOPS))
+			}
+		}
+		if procStats := cgroupStats.ProcStats; procStats != nil {
+			tbl.AddRow("Open Files:", fmt.Sprintf(
+				"%d (%d files, %d sockets, %d pipes, %d anon inodes, %d other)", procStats.NumFD, procStats.FDs.Files,
+				procStats.FDs.Sockets, procStats.FDs.Pipes, procStats.FDs.AnonInodes, procStats.FDs.Other))
+			if closest := procStats.ClosestToFDLimit; closest != nil {
+				tbl.AddRow("Closest to FD Limit:", fmt.Sprintf("pid %d, %s", closest.PID,
+					common.DisplayRatio(closest.NumFDs, closest.MaxFDs, common.WithTotal())))
+			}
+		}
+		if cgroupStats.Network != nil {
+			for _, namespace := range cgroupStats.Network.Namespaces {
+				host := ""
+				if namespace.HostNamespace {
+					host = ", host"
+				}
+				tbl.AddRow(fmt.Sprintf("Network Namespace (%d):", namespace.Inode), fmt.Sprintf(
+					"%d processes%s, %d TCP sockets (%s), %d UDP sockets (%s)", namespace.NumProcesses, host,
+					namespace.TCPStats.Sockets, common.FormatBytes(namespace.TCPStats.SocketMemory),
+					namespace.UDPStats.Sockets, common.FormatBytes(namespace.UDPStats.SocketMemory)))
+				interfaceNames := make([]string, 0, len(namespace.Interfaces))
+				for name := range namespace.Interfaces {
+					interfaceNames = append(interfaceNames, name)
+				}
+				sort.Strings(interfaceNames)
+				for _, name := range interfaceNames {
+					iface := namespace.Interfaces[name]
+					tbl.AddRow(fmt.Sprintf("Network Interface (%d/%s):", namespace.Inode, name), fmt.Sprintf(
+						"%s (%s/s) %d packets %d errors %d dropped (RX) %s (%s/s) %d packets %d errors %d dropped (TX)",
+						common.FormatBytes(iface.RxBytes), common.FormatBytes(uint64(iface.RxBytesPerSec)),
+						iface.RxPackets, iface.RxErrors, iface.RxDropped,
+						common.FormatBytes(iface.TxBytes), common.FormatBytes(uint64(iface.TxBytesPerSec)),
+						iface.TxPackets, iface.TxErrors, iface.TxDropped))
+				}
+			}
+			if unknown := cgroupStats.Network.UnknownNamespace; unknown != nil {
+				tbl.AddRow("Network Namespace (unknown):", fmt.Sprintf(
+					"%d processes, %d TCP sockets (%s), %d UDP sockets (%s)", unknown.NumProcesses,
+					unknown.TCPStats.Sockets, common.FormatBytes(unknown.TCPStats.SocketMemory),
+					unknown.UDPStats.Sockets, common.FormatBytes(unknown.UDPStats.SocketMemory)))
+			}
+			if tcpStates := formatTCPStates(cgroupStats.Network.TCPStats.States); tcpStates != "" {
+				tbl.AddRow("TCP States:", tcpStates)
+			}
+			if ports := cgroupStats.Network.TCPStats.ListeningPorts; len(ports) > 0 {
+				tbl.AddRow("Listening Ports:", strings.Trim(fmt.Sprint(ports), "[]"))
+			}
+		}
+		for _, name := range common.SortedRateNames(cgroupStats.Rates) {
+			tbl.AddRow(fmt.Sprintf("Rate (%s):", name), common.FormatRate(cgroupStats.Rates[name]))
+		}
 	}
 
 	tbl.Print()
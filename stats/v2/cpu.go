@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	CPUMaxFile          = "cpu.max"
+	CPUSetEffectiveFile = "cpuset.cpus.effective"
+)
+
+// readCPULimit reads the quota of the cgroup and each of its ancestors from cpu.max, and the CPUs on which the cgroup
+// may run from the closest cpuset.cpus.effective.
+func readCPULimit(cgroupRootDir string, cgroupPath string) *common.CPULimit {
+	var quotas []common.CPUQuota
+	numCPUs := 0
+	for _, dir := range common.CgroupDirs(cgroupRootDir, cgroupPath) {
+		if quota, err := readCPUMax(dir); err == nil {
+			quotas = append(quotas, quota)
+		}
+		if numCPUs == 0 {
+			if cpus, err := os.ReadFile(filepath.Join(dir, CPUSetEffectiveFile)); err == nil {
+				numCPUs, _ = common.ParseCPUList(string(cpus))
+			}
+		}
+	}
+	return common.NewCPULimit(quotas, numCPUs)
+}
+
+// readCPUMax parses cpu.max, which contains the quota and the period in microseconds, such as "50000 100000", or
+// "max 100000" if there is no quota.
+func readCPUMax(cgroupDir string) (common.CPUQuota, error) {
+	data, err := os.ReadFile(filepath.Join(cgroupDir, CPUMaxFile))
+	if err != nil {
+		return common.CPUQuota{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return common.CPUQuota{}, fmt.Errorf("malformed %s: %q", CPUMaxFile, data)
+	}
+	period, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return common.CPUQuota{}, fmt.Errorf("malformed %s: %q", CPUMaxFile, data)
+	}
+	if fields[0] == "max" {
+		return common.CPUQuota{PeriodUsec: period}, nil
+	}
+	quota, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return common.CPUQuota{}, fmt.Errorf("malformed %s: %q", CPUMaxFile, data)
+	}
+	return common.CPUQuota{QuotaUsec: quota, PeriodUsec: period}, nil
+}
@@ -0,0 +1,30 @@
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCPULimit(t *testing.T) {
+	// Given
+	rootDir := t.TempDir()
+	parentDir := filepath.Join(rootDir, "test.slice")
+	cgroupDir := filepath.Join(parentDir, "a.service")
+	assert.NoError(t, os.MkdirAll(cgroupDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(rootDir, CPUSetEffectiveFile), []byte("0-7\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(parentDir, CPUSetEffectiveFile), []byte("0-3\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(parentDir, CPUMaxFile), []byte("150000 100000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, CPUMaxFile), []byte("max 100000\n"), 0644))
+
+	// When
+	limit := readCPULimit(rootDir, "/test.slice/a.service")
+
+	// Then
+	assert.Equal(t, uint64(150000), limit.QuotaUsec)
+	assert.Equal(t, uint64(100000), limit.PeriodUsec)
+	assert.Equal(t, 4, limit.NumCPUs)
+	assert.Equal(t, 1.5, limit.Cores)
+}
@@ -0,0 +1,272 @@
+package v2
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	MemoryEventsFile = "memory.events"
+	PidsEventsFile   = "pids.events"
+	CgroupEventsFile = "cgroup.events"
+)
+
+// EventFiles are the files which are watched for events. The kernel generates a modification event on each of them
+// when one of their values changes, so they can be watched with inotify instead of being polled.
+var EventFiles = []string{MemoryEventsFile, PidsEventsFile, CgroupEventsFile}
+
+// CgroupEvent is a change of a value of an events file of a cgroup, such as oom_kill in memory.events or populated in
+// cgroup.events.
+type CgroupEvent struct {
+	// Timestamp is the time at which the change was noticed.
+	Timestamp time.Time
+	Cgroup    string
+	File      string
+	Key       string
+	// Value is the new value. Counters such as oom_kill are increased on each event, while states such as populated
+	// and frozen are either 0 or 1.
+	Value    uint64
+	Previous uint64
+}
+
+// readEventsFile parses a flat keyed file such as memory.events, which has the following format:
+//
+//	low 0
+//	high 0
+//	max 3
+//	oom 1
+//	oom_kill 1
+func readEventsFile(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+		values[fields[0]] = value
+	}
+	return values, scanner.Err()
+}
+
+// watchedFile is an events file which is being watched, along with its last known values.
+type watchedFile struct {
+	path     string
+	cgroup   string
+	filename string
+	values   map[string]uint64
+}
+
+// EventWatcher watches the events files of every cgroup with a prefix, including cgroups which are created after it
+// starts, and reports each value which changes.
+type EventWatcher struct {
+	rootDir string
+	watcher *fsnotify.Watcher
+	// tree reports the cgroups which are created under the prefix.
+	tree *common.TreeWatcher
+	// files are keyed by their path.
+	files map[string]*watchedFile
+	// cgroupDirs contains the name of each watched cgroup, keyed by the path of its directory. Directories are watched
+	// so that events files which appear later, such as when a controller is enabled, are watched as well.
+	cgroupDirs map[string]string
+}
+
+// NewEventWatcher watches the events files of every cgroup with the given prefix under the given cgroup root directory.
+// Files which do not exist, such as memory.events for cgroups without the memory controller, are skipped until they
+// are created.
+func NewEventWatcher(rootDir string, cgroupPrefix string) (*EventWatcher, error) {
+	tree, err := common.NewTreeWatcher(rootDir, cgroupPrefix)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		_ = tree.Close()
+		return nil, fmt.Errorf("could not create watcher: %w", err)
+	}
+
+	w := &EventWatcher{
+		rootDir:    rootDir,
+		watcher:    watcher,
+		tree:       tree,
+		files:      map[string]*watchedFile{},
+		cgroupDirs: map[string]string{},
+	}
+	for _, cgroup := range tree.Cgroups() {
+		if err = w.watchCgroup(cgroup); err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// watchCgroup watches the directory of the given cgroup, along with each of its events files which exist.
+func (w *EventWatcher) watchCgroup(cgroup string) error {
+	dir := filepath.Join(w.rootDir, cgroup)
+	if err := w.watcher.Add(dir); err != nil {
+		// The cgroup was removed before it could be watched.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("could not watch %s: %w", dir, err)
+	}
+	w.cgroupDirs[dir] = cgroup
+	for _, filename := range EventFiles {
+		if err := w.watchFile(cgroup, filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchFile watches an events file of the given cgroup, unless it does not exist or is already watched.
+func (w *EventWatcher) watchFile(cgroup string, filename string) error {
+	path := filepath.Join(w.rootDir, cgroup, filename)
+	if _, watched := w.files[path]; watched {
+		return nil
+	}
+	values, err := readEventsFile(path)
+	if err != nil {
+		return nil
+	}
+	if err = w.watcher.Add(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("could not watch %s: %w", path, err)
+	}
+	w.files[path] = &watchedFile{
+		path:     path,
+		cgroup:   cgroup,
+		filename: filename,
+		values:   values,
+	}
+	return nil
+}
+
+// NumWatchedFiles returns the number of events files being watched.
+func (w *EventWatcher) NumWatchedFiles() int {
+	return len(w.files)
+}
+
+// Run calls the given handler for each change of a watched value, until the watcher is closed or the handler returns
+// an error.
+func (w *EventWatcher) Run(handler func(*CgroupEvent) error) error {
+	created := make(chan string)
+	treeErrs := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		treeErrs <- w.tree.Run(func(eventType common.LifecycleEventType, cgroup string) error {
+			if eventType != common.CgroupCreated {
+				return nil
+			}
+			select {
+			case created <- cgroup:
+			case <-done:
+			}
+			return nil
+		})
+	}()
+
+	for {
+		select {
+		case cgroup := <-created:
+			if err := w.watchCgroup(cgroup); err != nil {
+				return err
+			}
+		case err := <-treeErrs:
+			return err
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if err := w.handleEvent(event, handler); err != nil {
+				return err
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("error watching events: %w", err)
+		}
+	}
+}
+
+func (w *EventWatcher) handleEvent(event fsnotify.Event, handler func(*CgroupEvent) error) error {
+	if cgroup, ok := w.cgroupDirs[filepath.Dir(event.Name)]; ok && event.Has(fsnotify.Create) &&
+		slices.Contains(EventFiles, filepath.Base(event.Name)) {
+		return w.watchFile(cgroup, filepath.Base(event.Name))
+	}
+	if event.Has(fsnotify.Remove) {
+		// The cgroup was removed.
+		delete(w.files, event.Name)
+		delete(w.cgroupDirs, event.Name)
+		return nil
+	}
+	file, watched := w.files[event.Name]
+	if watched && event.Has(fsnotify.Write) {
+		for _, e := range file.update(time.Now()) {
+			if err := handler(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// update re-reads the file, and returns an event for each value which changed, ordered by key.
+func (f *watchedFile) update(timestamp time.Time) []*CgroupEvent {
+	values, err := readEventsFile(f.path)
+	// The file may be empty while it is being rewritten, which is not a change.
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+
+	var events []*CgroupEvent
+	for key, value := range values {
+		if previous := f.values[key]; value != previous {
+			events = append(events, &CgroupEvent{
+				Timestamp: timestamp,
+				Cgroup:    f.cgroup,
+				File:      f.filename,
+				Key:       key,
+				Value:     value,
+				Previous:  previous,
+			})
+		}
+	}
+	f.values = values
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
+
+// Close stops watching the events files and the cgroup hierarchy.
+func (w *EventWatcher) Close() error {
+	return errors.Join(w.watcher.Close(), w.tree.Close())
+}
@@ -0,0 +1,97 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventWatcher(t *testing.T) {
+	// Given
+	rootDir := t.TempDir()
+	cgroupDir := filepath.Join(rootDir, "system.slice", "a.service")
+	assert.NoError(t, os.MkdirAll(cgroupDir, 0755))
+	memoryEventsPath := filepath.Join(cgroupDir, MemoryEventsFile)
+	assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("low 0\nhigh 2\nmax 0\noom 0\noom_kill 0\n"), 0644))
+
+	watcher, err := NewEventWatcher(rootDir, "/system.slice")
+	assert.NoError(t, err)
+	defer watcher.Close()
+	assert.Equal(t, 1, watcher.NumWatchedFiles())
+
+	events := make(chan *CgroupEvent, 10)
+	go func() {
+		_ = watcher.Run(func(event *CgroupEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// When
+	assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("low 0\nhigh 2\nmax 0\noom 1\noom_kill 1\n"), 0644))
+
+	// Then
+	var received []*CgroupEvent
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-timeout:
+			t.Fatalf("expected 2 events, received %d", len(received))
+		}
+	}
+	assert.Equal(t, "/system.slice/a.service", received[0].Cgroup)
+	assert.Equal(t, MemoryEventsFile, received[0].File)
+	assert.Equal(t, "oom", received[0].Key)
+	assert.Equal(t, "oom_kill", received[1].Key)
+	assert.Equal(t, uint64(1), received[1].Value)
+	assert.Equal(t, uint64(0), received[1].Previous)
+}
+
+func TestEventWatcher_CreatedCgroup(t *testing.T) {
+	// Given
+	rootDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "system.slice", "a.service"), 0755))
+
+	watcher, err := NewEventWatcher(rootDir, "/system.slice")
+	assert.NoError(t, err)
+	defer watcher.Close()
+
+	events := make(chan *CgroupEvent, 10)
+	go func() {
+		_ = watcher.Run(func(event *CgroupEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// When
+	jobDir := filepath.Join(rootDir, "system.slice", "job.scope")
+	assert.NoError(t, os.Mkdir(jobDir, 0755))
+	memoryEventsPath := filepath.Join(jobDir, MemoryEventsFile)
+	assert.NoError(t, os.WriteFile(memoryEventsPath, []byte("oom_kill 0\n"), 0644))
+
+	// Then
+	// The file is rewritten until a change is reported, since it is only watched once the watcher notices it.
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for oomKills := 1; ; oomKills++ {
+		select {
+		case event := <-events:
+			assert.Equal(t, "/system.slice/job.scope", event.Cgroup)
+			assert.Equal(t, MemoryEventsFile, event.File)
+			assert.Equal(t, "oom_kill", event.Key)
+			return
+		case <-ticker.C:
+			assert.NoError(t, os.WriteFile(memoryEventsPath, []byte(fmt.Sprintf("oom_kill %d\n", oomKills)), 0644))
+		case <-timeout:
+			t.Fatal("expected an event of a cgroup created after the watcher started")
+		}
+	}
+}
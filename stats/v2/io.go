@@ -0,0 +1,122 @@
+package v2
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const IOStatFile = "io.stat"
+
+// readIOStats parses the io.stat file of a cgroup, which contains one line per block device in the following format:
+//
+//	8:0 rbytes=90112 wbytes=0 rios=22 wios=0 dbytes=0 dios=0
+func readIOStats(cgroupDir string) (map[string]*IODeviceStats, error) {
+	f, err := os.Open(filepath.Join(cgroupDir, IOStatFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	devices := map[string]*IODeviceStats{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, err := common.ParseDeviceNumber(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", IOStatFile, err)
+		}
+		device := &IODeviceStats{
+			Major: major,
+			Minor: minor,
+		}
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				return nil, fmt.Errorf("could not parse %s: malformed field %q", IOStatFile, field)
+			}
+			// Other controllers add fields which are not counters, such as cost.vrate=100.00 with iocost or
+			// depth=max with iolatency, so only the known counters are parsed.
+			var counter *uint64
+			switch key {
+			case "rbytes":
+				counter = &device.ReadBytes
+			case "wbytes":
+				counter = &device.WriteBytes
+			case "rios":
+				counter = &device.ReadIOs
+			case "wios":
+				counter = &device.WriteIOs
+			case "dbytes":
+				counter = &device.DiscardBytes
+			case "dios":
+				counter = &device.DiscardIOs
+			default:
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s: %w", IOStatFile, err)
+			}
+			*counter = n
+		}
+		devices[fields[0]] = device
+	}
+	return devices, scanner.Err()
+}
+
+// withRates sets the per-second throughput and IOPS of each device from the increase of its counters since the
+// previous sample. Devices which were not present in the previous sample report a rate of zero.
+func (i *IOStats) withRates(deltas *common.CounterDeltas) {
+	for deviceName, device := range i.Devices {
+		device.ReadBytesPerSec = deltas.Rate(ioCounterName(deviceName, "read_bytes"))
+		device.WriteBytesPerSec = deltas.Rate(ioCounterName(deviceName, "write_bytes"))
+		device.ReadIOPS = deltas.Rate(ioCounterName(deviceName, "read_ios"))
+		device.WriteIOPS = deltas.Rate(ioCounterName(deviceName, "write_ios"))
+	}
+}
+
+// addCounters adds the counters of each device to the given sample.
+func (i *IOStats) addCounters(sample *common.CounterSample) {
+	for deviceName, device := range i.Devices {
+		sample.Counters[ioCounterName(deviceName, "read_bytes")] = device.ReadBytes
+		sample.Counters[ioCounterName(deviceName, "write_bytes")] = device.WriteBytes
+		sample.Counters[ioCounterName(deviceName, "read_ios")] = device.ReadIOs
+		sample.Counters[ioCounterName(deviceName, "write_ios")] = device.WriteIOs
+		sample.Counters[ioCounterName(deviceName, "discard_bytes")] = device.DiscardBytes
+		sample.Counters[ioCounterName(deviceName, "discard_ios")] = device.DiscardIOs
+	}
+}
+
+func ioCounterName(deviceName string, counter string) string {
+	return fmt.Sprintf("io.%s.%s", deviceName, counter)
+}
+
+// Total returns the sum of the stats across all block devices.
+func (i *IOStats) Total() *IODeviceStats {
+	total := &IODeviceStats{}
+	if i == nil {
+		return total
+	}
+	for _, device := range i.Devices {
+		total.ReadBytes += device.ReadBytes
+		total.WriteBytes += device.WriteBytes
+		total.ReadIOs += device.ReadIOs
+		total.WriteIOs += device.WriteIOs
+		total.DiscardBytes += device.DiscardBytes
+		total.DiscardIOs += device.DiscardIOs
+		total.ReadBytesPerSec += device.ReadBytesPerSec
+		total.WriteBytesPerSec += device.WriteBytesPerSec
+		total.ReadIOPS += device.ReadIOPS
+		total.WriteIOPS += device.WriteIOPS
+	}
+	return total
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadIOStats(t *testing.T) {
+	// Given
+	cgroupDir := t.TempDir()
+	content := "8:0 rbytes=90112 wbytes=4096 rios=22 wios=1 dbytes=0 dios=0\n" +
+		"259:0 rbytes=1024 wbytes=0 rios=1 wios=0\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, IOStatFile), []byte(content), 0644))
+
+	// When
+	devices, err := readIOStats(cgroupDir)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*IODeviceStats{
+		"8:0":   {Major: 8, Minor: 0, ReadBytes: 90112, WriteBytes: 4096, ReadIOs: 22, WriteIOs: 1},
+		"259:0": {Major: 259, Minor: 0, ReadBytes: 1024, ReadIOs: 1},
+	}, devices)
+}
+
+func TestReadIOStats_SkipsUnknownFields(t *testing.T) {
+	// Given
+	cgroupDir := t.TempDir()
+	content := "8:0 rbytes=90112 wbytes=4096 rios=22 wios=1 dbytes=0 dios=0 cost.vrate=100.00 depth=max\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, IOStatFile), []byte(content), 0644))
+
+	// When
+	devices, err := readIOStats(cgroupDir)
+
+	// Then
+	assert.NoError(t, err, "Fields of iocost and iolatency should not fail the whole file.")
+	assert.Equal(t, map[string]*IODeviceStats{
+		"8:0": {Major: 8, Minor: 0, ReadBytes: 90112, WriteBytes: 4096, ReadIOs: 22, WriteIOs: 1},
+	}, devices)
+}
+
+func TestIOStats_WithRates(t *testing.T) {
+	// Given
+	prevIO := &IOStats{
+		Devices: map[string]*IODeviceStats{
+			"8:0": {ReadBytes: 1000, WriteBytes: 500, ReadIOs: 10, WriteIOs: 5},
+		},
+	}
+	io := &IOStats{
+		Devices: map[string]*IODeviceStats{
+			"8:0":   {ReadBytes: 3000, WriteBytes: 100, ReadIOs: 30, WriteIOs: 15},
+			"259:0": {ReadBytes: 4096, ReadIOs: 1},
+		},
+	}
+	store := common.NewCounterStore()
+	prevSample := common.NewCounterSample(1_000_000, 0)
+	prevIO.addCounters(prevSample)
+	sample := common.NewCounterSample(3_000_000, 0)
+	io.addCounters(sample)
+	store.Update("/a.service", prevSample)
+
+	// When
+	io.withRates(store.Update("/a.service", sample))
+
+	// Then
+	assert.Equal(t, 1000.0, io.Devices["8:0"].ReadBytesPerSec)
+	assert.Equal(t, 0.0, io.Devices["8:0"].WriteBytesPerSec, "A counter reset should not report a rate.")
+	assert.Equal(t, 10.0, io.Devices["8:0"].ReadIOPS)
+	assert.Equal(t, 5.0, io.Devices["8:0"].WriteIOPS)
+	assert.Equal(t, 0.0, io.Devices["259:0"].ReadBytesPerSec, "A new device should not report a rate.")
+	assert.Equal(t, 1000.0, io.Total().ReadBytesPerSec)
+}
@@ -0,0 +1,200 @@
+package v2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	usecPerSecond = 1e6
+)
+
+func toMetrics(c *CgroupStats) *common.CgroupMetrics {
+	var metrics []*common.Metric
+
+	if c.CPU != nil {
+		metrics = append(metrics,
+			common.NewCounter("cpu_usage_seconds_total", "Total CPU time consumed by the cgroup.",
+				float64(c.CPU.UsageInUsec)/usecPerSecond),
+			common.NewCounter("cpu_user_seconds_total", "User CPU time consumed by the cgroup.",
+				float64(c.CPU.UserTimeInUsec)/usecPerSecond),
+			common.NewCounter("cpu_system_seconds_total", "System CPU time consumed by the cgroup.",
+				float64(c.CPU.SystemTimeInUsec)/usecPerSecond),
+			common.NewCounter("cpu_periods_total", "Number of periods in which the cgroup was runnable.",
+				float64(c.CPU.NumRunnablePeriods)),
+			common.NewCounter("cpu_throttled_periods_total", "Number of periods in which the cgroup was throttled.",
+				float64(c.CPU.NumThrottledPeriods)),
+			common.NewCounter("cpu_throttled_seconds_total", "Total time the cgroup was throttled.",
+				float64(c.CPU.ThrottledTimeInUsec)/usecPerSecond),
+		)
+		if c.CPU.Limit != nil {
+			metrics = append(metrics, common.NewGauge("cpu_limit_cores",
+				"CPU bandwidth available to the cgroup, from its quota and cpuset.", c.CPU.Limit.Cores))
+		}
+	}
+	if c.PID != nil {
+		metrics = append(metrics,
+			common.NewGauge("pids_current", "Number of processes in the cgroup.", float64(c.PID.Current)),
+			common.NewGauge("pids_limit", "Maximum number of processes in the cgroup.", float64(c.PID.Limit)),
+		)
+	}
+	if c.Memory != nil {
+		metrics = append(metrics,
+			common.NewGauge("memory_usage_bytes", "Memory used by the cgroup.", float64(c.Memory.Usage)),
+			common.NewGauge("memory_limit_bytes", "Memory limit of the cgroup.", float64(c.Memory.UsageLimit)),
+			common.NewGauge("memory_anon_bytes", "Anonymous memory used by the cgroup.",
+				float64(c.Memory.Anon.Total)),
+			common.NewGauge("memory_file_bytes", "Memory used to cache filesystem data.",
+				float64(c.Memory.Filesystem.Current)),
+			common.NewGauge("memory_kernel_bytes", "Kernel memory used by the cgroup.",
+				float64(c.Memory.Kernel.Slab+c.Memory.Kernel.Stack)),
+			common.NewGauge("memory_swap_bytes", "Swap used by the cgroup.", float64(c.Memory.Swap.Usage)),
+			common.NewCounter("memory_page_faults_total", "Number of page faults incurred by the cgroup.",
+				float64(c.Memory.PageCache.Fault)),
+			common.NewCounter("memory_major_page_faults_total", "Number of major page faults incurred by the cgroup.",
+				float64(c.Memory.PageCache.MajorFault)),
+		)
+	}
+	if c.MemoryEvent != nil {
+		metrics = append(metrics,
+			common.NewCounter("memory_oom_events_total", "Number of times the cgroup reached its memory limit.",
+				float64(c.MemoryEvent.NumOomEvents)),
+			common.NewCounter("memory_oom_kill_events_total", "Number of processes in the cgroup killed by the OOM killer.",
+				float64(c.MemoryEvent.NumOomKillEvents)),
+			common.NewCounter("memory_high_events_total", "Number of times the cgroup exceeded its high memory boundary.",
+				float64(c.MemoryEvent.High)),
+			common.NewCounter("memory_max_events_total", "Number of times the cgroup was about to exceed its max memory boundary.",
+				float64(c.MemoryEvent.Max)),
+		)
+	}
+	if c.ProcStats != nil {
+		metrics = append(metrics,
+			common.NewGauge("open_fds", "Number of open file descriptors of processes in the cgroup.",
+				float64(c.ProcStats.NumFD)),
+		)
+		for _, fdType := range []struct {
+			name  string
+			count uint64
+		}{
+			{"file", c.ProcStats.FDs.Files},
+			{"socket", c.ProcStats.FDs.Sockets},
+			{"pipe", c.ProcStats.FDs.Pipes},
+			{"anon_inode", c.ProcStats.FDs.AnonInodes},
+			{"other", c.ProcStats.FDs.Other},
+		} {
+			metrics = append(metrics, common.NewGauge("open_fds_by_type",
+				"Number of open file descriptors of processes in the cgroup, by type.",
+				float64(fdType.count)).WithLabel("type", fdType.name))
+		}
+		if closest := c.ProcStats.ClosestToFDLimit; closest != nil {
+			metrics = append(metrics, common.NewGauge("open_fds_limit_usage_percent",
+				"Highest number of open file descriptors of a process in the cgroup, as a percentage of its RLIMIT_NOFILE.",
+				closest.UsagePercent()))
+		}
+	}
+	if c.Network != nil {
+		metrics = append(metrics,
+			common.NewGauge("tcp_sockets", "Number of TCP sockets in use.", float64(c.Network.TCPStats.Sockets)),
+			common.NewGauge("udp_sockets", "Number of UDP sockets in use.", float64(c.Network.UDPStats.Sockets)),
+			common.NewGauge("network_namespaces", "Number of network namespaces used by the cgroup.",
+				float64(len(c.Network.Namespaces))),
+			common.NewGauge("network_host_namespace",
+				"Whether the cgroup uses the network namespace of the host, so that its socket counts are host-wide.",
+				boolToFloat(c.Network.SharesHostNamespace)),
+			common.NewGauge("network_unknown_namespace_processes",
+				"Number of processes whose network namespace could not be read, counted as sharing one namespace.",
+				float64(c.Network.UnknownNamespaceProcesses())),
+		)
+		for _, state := range sortedTCPStates(c.Network.TCPStats.States) {
+			metrics = append(metrics, common.NewGauge("tcp_connections",
+				"Number of TCP sockets of the processes in the cgroup, by state.",
+				float64(c.Network.TCPStats.States[state])).WithLabel("state", state))
+		}
+		metrics = append(metrics, toNetworkInterfaceMetrics(c.Network)...)
+	}
+	if c.IO != nil {
+		deviceNames := make([]string, 0, len(c.IO.Devices))
+		for deviceName := range c.IO.Devices {
+			deviceNames = append(deviceNames, deviceName)
+		}
+		sort.Strings(deviceNames)
+		for _, deviceName := range deviceNames {
+			device := c.IO.Devices[deviceName]
+			metrics = append(metrics,
+				common.NewCounter("io_read_bytes_total", "Number of bytes read from the device.",
+					float64(device.ReadBytes)).WithLabel("device", deviceName),
+				common.NewCounter("io_write_bytes_total", "Number of bytes written to the device.",
+					float64(device.WriteBytes)).WithLabel("device", deviceName),
+				common.NewCounter("io_reads_total", "Number of read IOs issued to the device.",
+					float64(device.ReadIOs)).WithLabel("device", deviceName),
+				common.NewCounter("io_writes_total", "Number of write IOs issued to the device.",
+					float64(device.WriteIOs)).WithLabel("device", deviceName),
+			)
+		}
+	}
+	for i, pressure := range c.Pressure.byResource() {
+		resource := strings.ToLower(pressureResourceNames[i])
+		if some := pressure.getSome(); some != nil {
+			metrics = append(metrics, newPressureMetric(resource, "some", some))
+		}
+		if full := pressure.getFull(); full != nil {
+			metrics = append(metrics, newPressureMetric(resource, "full", full))
+		}
+	}
+
+	return &common.CgroupMetrics{
+		Name:    c.Name,
+		Metrics: metrics,
+	}
+}
+
+func newPressureMetric(resource string, kind string, p *PressureData) *common.Metric {
+	return common.NewCounter("pressure_stalled_seconds_total", "Total time tasks in the cgroup were stalled on a resource.",
+		float64(p.Total)/usecPerSecond).WithLabel("resource", resource).WithLabel("kind", kind)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0.0
+}
+
+func toNetworkInterfaceMetrics(network *NetworkStats) []*common.Metric {
+	var metrics []*common.Metric
+	for _, namespace := range network.Namespaces {
+		interfaceNames := make([]string, 0, len(namespace.Interfaces))
+		for name := range namespace.Interfaces {
+			interfaceNames = append(interfaceNames, name)
+		}
+		sort.Strings(interfaceNames)
+		netNS := fmt.Sprintf("%d", namespace.Inode)
+		for _, name := range interfaceNames {
+			iface := namespace.Interfaces[name]
+			for _, metric := range []*common.Metric{
+				common.NewCounter("network_receive_bytes_total", "Number of bytes received by the interface.",
+					float64(iface.RxBytes)),
+				common.NewCounter("network_receive_packets_total", "Number of packets received by the interface.",
+					float64(iface.RxPackets)),
+				common.NewCounter("network_receive_errors_total", "Number of receive errors of the interface.",
+					float64(iface.RxErrors)),
+				common.NewCounter("network_receive_dropped_total", "Number of received packets dropped by the interface.",
+					float64(iface.RxDropped)),
+				common.NewCounter("network_transmit_bytes_total", "Number of bytes transmitted by the interface.",
+					float64(iface.TxBytes)),
+				common.NewCounter("network_transmit_packets_total", "Number of packets transmitted by the interface.",
+					float64(iface.TxPackets)),
+				common.NewCounter("network_transmit_errors_total", "Number of transmit errors of the interface.",
+					float64(iface.TxErrors)),
+				common.NewCounter("network_transmit_dropped_total", "Number of transmitted packets dropped by the interface.",
+					float64(iface.TxDropped)),
+			} {
+				metrics = append(metrics, metric.WithLabel("netns", netNS).WithLabel("interface", name))
+			}
+		}
+	}
+	return metrics
+}
@@ -1,5 +1,7 @@
 package v2
 
+import "github.com/strategicpause/cgstat/stats/common"
+
 type CPUStats struct {
 	// SystemTime in Microseconds.
 	SystemTime int64
@@ -17,11 +19,40 @@ type CPUStats struct {
 	SystemTimeInUsec uint64
 	// Userspace CPU usage, in microseconds.
 	UserTimeInUsec uint64
+	// The CPU bandwidth available to the cgroup.
+	Limit *common.CPULimit
+	// Throttling since the previous sample, which is nil for the first sample of a cgroup.
+	Throttling *common.ThrottlingRate
 }
 
 type ProcStats struct {
 	// The total number of open file descriptors for processes in the container
 	NumFD uint64
+	// FDs is the number of open file descriptors of each type.
+	FDs FDCounts
+	// ClosestToFDLimit is the process with the highest number of open file descriptors relative to its
+	// RLIMIT_NOFILE, or nil if no process has a known limit.
+	ClosestToFDLimit *ProcessFDUsage
+}
+
+// FDCounts is the number of open file descriptors of each type, by where their /proc/<pid>/fd link points.
+type FDCounts struct {
+	// Regular files, directories and devices.
+	Files   uint64
+	Sockets uint64
+	Pipes   uint64
+	// Anonymous inodes, such as eventfd, epoll and timerfd descriptors.
+	AnonInodes uint64
+	// Other descriptors, such as those which were closed before their link could be read.
+	Other uint64
+}
+
+// ProcessFDUsage is the number of open file descriptors of a process compared to its limit.
+type ProcessFDUsage struct {
+	PID    int
+	NumFDs uint64
+	// MaxFDs is the soft RLIMIT_NOFILE of the process.
+	MaxFDs uint64
 }
 
 type PidStats struct {
@@ -184,6 +215,13 @@ type TCPNetworkStats struct {
 	RxQueueLength uint64
 	// TCP transfer queue length.
 	TxQueueLength uint64
+	// States is the number of TCP sockets of the cgroup in each state, such as ESTABLISHED or CLOSE_WAIT, keyed by
+	// the name of the state. Unlike Sockets, only the sockets opened by the processes of the cgroup are counted,
+	// along with sockets which no longer belong to a process, such as those in TIME_WAIT, if the namespace is not the
+	// host namespace.
+	States map[string]uint64
+	// ListeningPorts are the ports the processes of the cgroup listen on, in ascending order.
+	ListeningPorts []uint64
 }
 
 type UDPNetworkStats struct {
@@ -197,9 +235,143 @@ type UDPNetworkStats struct {
 	TxQueueLength uint64
 }
 
+// NetworkStats contains the socket stats of the network namespaces used by the processes of a cgroup. Socket stats
+// describe a whole network namespace, so each namespace is only counted once, however many processes use it.
 type NetworkStats struct {
+	// TCPStats and UDPStats are the totals across every network namespace.
 	TCPStats *TCPNetworkStats
 	UDPStats *UDPNetworkStats
+	// Namespaces contains the stats of each network namespace, ordered by inode.
+	Namespaces []*NetworkNamespaceStats
+	// UnknownNamespace contains the processes whose network namespace could not be read, such as those of other users
+	// when not running as root, or nil if there are none. Their sockets are read through the first of these processes,
+	// so they are assumed to share its namespace. Its Inode is zero.
+	UnknownNamespace *NetworkNamespaceStats
+	// SharesHostNamespace is true if a process of the cgroup is in the network namespace of the host, in which case
+	// the socket stats include every socket of the host rather than only those of the cgroup.
+	SharesHostNamespace bool
+}
+
+type NetworkNamespaceStats struct {
+	// Inode of the network namespace, as found in /proc/<pid>/ns/net.
+	Inode uint64
+	// HostNamespace is true if this is the network namespace of the host.
+	HostNamespace bool
+	// NumProcesses is the number of processes of the cgroup in the network namespace.
+	NumProcesses uint64
+	TCPStats     *TCPNetworkStats
+	UDPStats     *UDPNetworkStats
+	// Interfaces contains the traffic of each network interface of the namespace, keyed by interface name. It is
+	// only read for namespaces other than the host namespace, since the interfaces of the host carry the traffic of
+	// every process on the host.
+	Interfaces map[string]*NetworkInterfaceStats
+}
+
+// NetworkInterfaceStats contains the traffic of a network interface, from /proc/<pid>/net/dev.
+type NetworkInterfaceStats struct {
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrors  uint64
+	RxDropped uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrors  uint64
+	TxDropped uint64
+	// Bytes received per second since the previous sample.
+	RxBytesPerSec float64
+	// Bytes transmitted per second since the previous sample.
+	TxBytesPerSec float64
+	// Packets received per second since the previous sample.
+	RxPacketsPerSec float64
+	// Packets transmitted per second since the previous sample.
+	TxPacketsPerSec float64
+}
+
+// PressureData describes how much time tasks were stalled waiting on a resource.
+type PressureData struct {
+	// Avg10 is the percentage of time tasks were stalled over the last 10 seconds.
+	Avg10 float64
+	// Avg60 is the percentage of time tasks were stalled over the last 60 seconds.
+	Avg60 float64
+	// Avg300 is the percentage of time tasks were stalled over the last 300 seconds.
+	Avg300 float64
+	// Total is the absolute stall time, in microseconds.
+	Total uint64
+}
+
+type PressureStats struct {
+	// Some is the share of time in which at least some tasks were stalled on the resource.
+	Some *PressureData
+	// Full is the share of time in which all non-idle tasks were stalled on the resource simultaneously.
+	Full *PressureData
+}
+
+// PressureStallStats contains the Pressure Stall Information (PSI) of a cgroup. A resource is nil if the kernel does
+// not report pressure for it.
+type PressureStallStats struct {
+	CPU    *PressureStats
+	Memory *PressureStats
+	IO     *PressureStats
+}
+
+type IODeviceStats struct {
+	// Major device number.
+	Major uint64
+	// Minor device number.
+	Minor uint64
+	// Number of bytes read from the device.
+	ReadBytes uint64
+	// Number of bytes written to the device.
+	WriteBytes uint64
+	// Number of read IOs issued to the device.
+	ReadIOs uint64
+	// Number of write IOs issued to the device.
+	WriteIOs uint64
+	// Number of bytes discarded on the device.
+	DiscardBytes uint64
+	// Number of discard IOs issued to the device.
+	DiscardIOs uint64
+	// Bytes read per second since the previous sample.
+	ReadBytesPerSec float64
+	// Bytes written per second since the previous sample.
+	WriteBytesPerSec float64
+	// Read IOs per second since the previous sample.
+	ReadIOPS float64
+	// Write IOs per second since the previous sample.
+	WriteIOPS float64
+}
+
+type IOStats struct {
+	// SystemTime in Microseconds.
+	SystemTime int64
+	// Devices contains the IO stats of each block device, keyed by its device name (such as nvme0n1p2), or by its
+	// major:minor device number if the name could not be resolved.
+	Devices map[string]*IODeviceStats
+}
+
+// pressureResourceNames are the names of the resources returned by PressureStallStats.byResource, in order.
+var pressureResourceNames = []string{"CPU", "Memory", "IO"}
+
+// byResource returns the pressure of CPU, memory and IO, in that order. Missing resources are returned as nil.
+func (p *PressureStallStats) byResource() []*PressureStats {
+	if p == nil {
+		return []*PressureStats{nil, nil, nil}
+	}
+	return []*PressureStats{p.CPU, p.Memory, p.IO}
+}
+
+func (p *PressureStats) getSome() *PressureData {
+	if p == nil {
+		return nil
+	}
+	return p.Some
+}
+
+func (p *PressureStats) getFull() *PressureData {
+	if p == nil {
+		return nil
+	}
+	return p.Full
 }
 
 type CgroupStats struct {
@@ -217,6 +389,14 @@ type CgroupStats struct {
 	MemoryEvent *MemoryEventStats
 	//
 	Network *NetworkStats
+	//
+	Pressure *PressureStallStats
+	//
+	IO *IOStats
+	// ID is the inode of the cgroup directory, which changes when the cgroup is removed and recreated.
+	ID uint64
+	// Rates contains the per-second rate of each counter since the previous sample, keyed by the name of the counter.
+	Rates map[string]float64
 }
 
 type CgroupStatsOpt func(*CgroupStats)
@@ -0,0 +1,379 @@
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/procfs"
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// readNetworkStats reads the socket stats of each network namespace used by the given processes once, through the
+// first process found in the namespace. Processes whose namespace could not be read cannot be told apart from
+// processes in other namespaces, so they are counted together in the unknown namespace. TCP states are looked up in
+// the socket tables of the given snapshot.
+func readNetworkStats(procDir string, processes []*ProcessInfo, snapshot *ProcessSnapshot) *NetworkStats {
+	namespacesByInode := map[uint64]*NetworkNamespaceStats{}
+	pidsByInode := map[uint64]int{}
+	socketInodesByInode := map[uint64]map[uint64]bool{}
+	var unknownNamespace *NetworkNamespaceStats
+	unknownPID := 0
+	for _, process := range processes {
+		if process.NetNS == 0 {
+			if unknownNamespace == nil {
+				unknownNamespace = &NetworkNamespaceStats{}
+				unknownPID = process.PID
+			}
+			unknownNamespace.NumProcesses++
+			continue
+		}
+		namespace, ok := namespacesByInode[process.NetNS]
+		if !ok {
+			namespace = &NetworkNamespaceStats{
+				Inode:         process.NetNS,
+				HostNamespace: process.NetNS == snapshot.HostNetNS,
+			}
+			namespacesByInode[process.NetNS] = namespace
+			pidsByInode[process.NetNS] = process.PID
+			socketInodesByInode[process.NetNS] = map[uint64]bool{}
+		}
+		namespace.NumProcesses++
+		for _, socketInode := range process.SocketInodes {
+			socketInodesByInode[process.NetNS][socketInode] = true
+		}
+	}
+
+	networkStats := &NetworkStats{
+		TCPStats: &TCPNetworkStats{},
+		UDPStats: &UDPNetworkStats{},
+	}
+	for inode, namespace := range namespacesByInode {
+		procPath := filepath.Join(procDir, strconv.Itoa(pidsByInode[inode]))
+		namespace.TCPStats, namespace.UDPStats = readNetworkNamespaceStats(procPath)
+		snapshot.tcpTables[inode].countStates(namespace.TCPStats, socketInodesByInode[inode], !namespace.HostNamespace)
+		if !namespace.HostNamespace {
+			namespace.Interfaces = readNetworkInterfaceStats(procPath)
+		}
+		networkStats.Namespaces = append(networkStats.Namespaces, namespace)
+		networkStats.TCPStats.add(namespace.TCPStats)
+		networkStats.UDPStats.add(namespace.UDPStats)
+		networkStats.SharesHostNamespace = networkStats.SharesHostNamespace || namespace.HostNamespace
+	}
+	sort.Slice(networkStats.Namespaces, func(i, j int) bool {
+		return networkStats.Namespaces[i].Inode < networkStats.Namespaces[j].Inode
+	})
+	// /proc/<pid>/net can be read for any process, unlike /proc/<pid>/ns/net. The interfaces of the unknown namespace
+	// are not read, since it may be the host namespace.
+	if unknownNamespace != nil {
+		unknownNamespace.TCPStats, unknownNamespace.UDPStats =
+			readNetworkNamespaceStats(filepath.Join(procDir, strconv.Itoa(unknownPID)))
+		networkStats.UnknownNamespace = unknownNamespace
+		networkStats.TCPStats.add(unknownNamespace.TCPStats)
+		networkStats.UDPStats.add(unknownNamespace.UDPStats)
+	}
+	return networkStats
+}
+
+// readNetworkNamespaceStats reads the TCP and UDP stats of the network namespace of the process with the given proc
+// directory.
+func readNetworkNamespaceStats(procPath string) (*TCPNetworkStats, *UDPNetworkStats) {
+	tcpStats := &TCPNetworkStats{}
+	udpStats := &UDPNetworkStats{}
+
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return tcpStats, udpStats
+	}
+	if tcpSummary, err := fs.NetTCPSummary(); err == nil {
+		tcpStats.TxQueueLength = tcpSummary.TxQueueLength
+		tcpStats.RxQueueLength = tcpSummary.RxQueueLength
+	}
+	if udpSummary, err := fs.NetUDPSummary(); err == nil {
+		udpStats.TxQueueLength = udpSummary.TxQueueLength
+		udpStats.RxQueueLength = udpSummary.RxQueueLength
+	}
+	if netSockStat, err := fs.NetSockstat(); err == nil {
+		for _, protocol := range netSockStat.Protocols {
+			if protocol.Protocol == "TCP" {
+				tcpStats.Sockets = uint64(protocol.InUse)
+				// The Mem value is reported in pages, so we need to convert pages to bytes in order to
+				// determine how much memory is being used.
+				if protocol.Mem != nil {
+					tcpStats.SocketMemory = uint64(*protocol.Mem * SocketPageSizeInBytes)
+				}
+			} else if protocol.Protocol == "UDP" {
+				udpStats.Sockets = uint64(protocol.InUse)
+				if protocol.Mem != nil {
+					udpStats.SocketMemory = uint64(*protocol.Mem * SocketPageSizeInBytes)
+				}
+			}
+		}
+	}
+	return tcpStats, udpStats
+}
+
+// TCP states as numbered in include/net/tcp_states.h, which is how they appear in the st column of /proc/net/tcp.
+var tcpStateNames = map[uint64]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+	12: "NEW_SYN_RECV",
+}
+
+// TCPListenState is the name of the state of listening sockets.
+const TCPListenState = "LISTEN"
+
+// tcpSocket is the state and local port of a TCP socket.
+type tcpSocket struct {
+	state string
+	port  uint64
+}
+
+// tcpSocketTable indexes the IPv4 and IPv6 TCP sockets of a network namespace by inode, so that the table of a
+// namespace is parsed once per sample, however many cgroups use the namespace.
+type tcpSocketTable struct {
+	byInode map[uint64]tcpSocket
+	// unowned is the number of sockets without an inode in each state, such as those in TIME_WAIT, which are no longer
+	// owned by a process.
+	unowned map[string]uint64
+}
+
+// readTCPSocketTable reads the TCP sockets of the network namespace of the process with the given proc directory.
+func readTCPSocketTable(procPath string) (*tcpSocketTable, error) {
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil, err
+	}
+	table := &tcpSocketTable{
+		byInode: map[uint64]tcpSocket{},
+		unowned: map[string]uint64{},
+	}
+	for _, read := range []func() (procfs.NetTCP, error){fs.NetTCP, fs.NetTCP6} {
+		// The tcp6 table does not exist if IPv6 is disabled.
+		sockets, err := read()
+		if err != nil {
+			continue
+		}
+		for _, socket := range sockets {
+			state, ok := tcpStateNames[socket.St]
+			if !ok {
+				state = fmt.Sprintf("%d", socket.St)
+			}
+			if socket.Inode == 0 {
+				table.unowned[state]++
+			} else {
+				table.byInode[socket.Inode] = tcpSocket{state: state, port: socket.LocalPort}
+			}
+		}
+	}
+	return table, nil
+}
+
+// countStates counts the sockets with one of the given inodes by state, along with the ports being listened on.
+// Sockets which are no longer owned by a process are only counted if includeUnowned is true.
+func (t *tcpSocketTable) countStates(tcpStats *TCPNetworkStats, socketInodes map[uint64]bool, includeUnowned bool) {
+	if t == nil {
+		return
+	}
+	tcpStats.States = map[string]uint64{}
+	listeningPorts := map[uint64]bool{}
+	for socketInode := range socketInodes {
+		socket, ok := t.byInode[socketInode]
+		if !ok {
+			continue
+		}
+		tcpStats.States[socket.state]++
+		if socket.state == TCPListenState {
+			listeningPorts[socket.port] = true
+		}
+	}
+	if includeUnowned {
+		for state, count := range t.unowned {
+			tcpStats.States[state] += count
+		}
+	}
+	tcpStats.ListeningPorts = sortedPorts(listeningPorts)
+}
+
+// sortedTCPStates returns the names of the given states in the order the kernel numbers them, followed by any unknown
+// states.
+func sortedTCPStates(states map[string]uint64) []string {
+	var sorted []string
+	for st := uint64(1); st <= uint64(len(tcpStateNames)); st++ {
+		if states[tcpStateNames[st]] > 0 {
+			sorted = append(sorted, tcpStateNames[st])
+		}
+	}
+	var unknown []string
+	for state := range states {
+		if !slices.Contains(sorted, state) && states[state] > 0 {
+			unknown = append(unknown, state)
+		}
+	}
+	sort.Strings(unknown)
+	return append(sorted, unknown...)
+}
+
+func sortedPorts(ports map[uint64]bool) []uint64 {
+	if len(ports) == 0 {
+		return nil
+	}
+	sorted := make([]uint64, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// readNetworkInterfaceStats reads the traffic of each network interface of the network namespace of the process with
+// the given proc directory.
+func readNetworkInterfaceStats(procPath string) map[string]*NetworkInterfaceStats {
+	fs, err := procfs.NewFS(procPath)
+	if err != nil {
+		return nil
+	}
+	netDev, err := fs.NetDev()
+	if err != nil {
+		return nil
+	}
+	interfaces := make(map[string]*NetworkInterfaceStats, len(netDev))
+	for name, line := range netDev {
+		interfaces[name] = &NetworkInterfaceStats{
+			RxBytes:   line.RxBytes,
+			RxPackets: line.RxPackets,
+			RxErrors:  line.RxErrors,
+			RxDropped: line.RxDropped,
+			TxBytes:   line.TxBytes,
+			TxPackets: line.TxPackets,
+			TxErrors:  line.TxErrors,
+			TxDropped: line.TxDropped,
+		}
+	}
+	return interfaces
+}
+
+// UnknownNamespaceProcesses returns the number of processes whose network namespace could not be read.
+func (n *NetworkStats) UnknownNamespaceProcesses() uint64 {
+	if n == nil || n.UnknownNamespace == nil {
+		return 0
+	}
+	return n.UnknownNamespace.NumProcesses
+}
+
+// LoopbackInterface carries traffic within a network namespace, so it is left out of InterfaceTotal.
+const LoopbackInterface = "lo"
+
+// InterfaceTotal returns the sum of the traffic of every network interface of every namespace, other than loopback
+// interfaces.
+func (n *NetworkStats) InterfaceTotal() *NetworkInterfaceStats {
+	total := &NetworkInterfaceStats{}
+	if n == nil {
+		return total
+	}
+	for _, namespace := range n.Namespaces {
+		total.add(namespace.InterfaceTotal())
+	}
+	return total
+}
+
+// InterfaceTotal returns the sum of the traffic of every network interface of the namespace, other than loopback
+// interfaces.
+func (n *NetworkNamespaceStats) InterfaceTotal() *NetworkInterfaceStats {
+	total := &NetworkInterfaceStats{}
+	for name, iface := range n.Interfaces {
+		if name != LoopbackInterface {
+			total.add(iface)
+		}
+	}
+	return total
+}
+
+func (i *NetworkInterfaceStats) add(other *NetworkInterfaceStats) {
+	i.RxBytes += other.RxBytes
+	i.RxPackets += other.RxPackets
+	i.RxErrors += other.RxErrors
+	i.RxDropped += other.RxDropped
+	i.TxBytes += other.TxBytes
+	i.TxPackets += other.TxPackets
+	i.TxErrors += other.TxErrors
+	i.TxDropped += other.TxDropped
+	i.RxBytesPerSec += other.RxBytesPerSec
+	i.TxBytesPerSec += other.TxBytesPerSec
+	i.RxPacketsPerSec += other.RxPacketsPerSec
+	i.TxPacketsPerSec += other.TxPacketsPerSec
+}
+
+// addCounters adds the counters of each network interface to the given sample. Counters are keyed by the inode of
+// their namespace, so that a recreated namespace starts over without a rate.
+func (n *NetworkStats) addCounters(sample *common.CounterSample) {
+	for _, namespace := range n.Namespaces {
+		for name, iface := range namespace.Interfaces {
+			sample.Counters[netCounterName(namespace.Inode, name, "rx_bytes")] = iface.RxBytes
+			sample.Counters[netCounterName(namespace.Inode, name, "rx_packets")] = iface.RxPackets
+			sample.Counters[netCounterName(namespace.Inode, name, "rx_errors")] = iface.RxErrors
+			sample.Counters[netCounterName(namespace.Inode, name, "rx_dropped")] = iface.RxDropped
+			sample.Counters[netCounterName(namespace.Inode, name, "tx_bytes")] = iface.TxBytes
+			sample.Counters[netCounterName(namespace.Inode, name, "tx_packets")] = iface.TxPackets
+			sample.Counters[netCounterName(namespace.Inode, name, "tx_errors")] = iface.TxErrors
+			sample.Counters[netCounterName(namespace.Inode, name, "tx_dropped")] = iface.TxDropped
+		}
+	}
+}
+
+// withRates sets the per-second traffic of each network interface from the increase of its counters since the
+// previous sample.
+func (n *NetworkStats) withRates(deltas *common.CounterDeltas) {
+	for _, namespace := range n.Namespaces {
+		for name, iface := range namespace.Interfaces {
+			iface.RxBytesPerSec = deltas.Rate(netCounterName(namespace.Inode, name, "rx_bytes"))
+			iface.TxBytesPerSec = deltas.Rate(netCounterName(namespace.Inode, name, "tx_bytes"))
+			iface.RxPacketsPerSec = deltas.Rate(netCounterName(namespace.Inode, name, "rx_packets"))
+			iface.TxPacketsPerSec = deltas.Rate(netCounterName(namespace.Inode, name, "tx_packets"))
+		}
+	}
+}
+
+func netCounterName(netNS uint64, interfaceName string, counter string) string {
+	return fmt.Sprintf("net.%d.%s.%s", netNS, interfaceName, counter)
+}
+
+func (t *TCPNetworkStats) add(other *TCPNetworkStats) {
+	if len(other.States) > 0 && t.States == nil {
+		t.States = map[string]uint64{}
+	}
+	for state, count := range other.States {
+		t.States[state] += count
+	}
+	if len(other.ListeningPorts) > 0 {
+		ports := map[uint64]bool{}
+		for _, port := range append(t.ListeningPorts, other.ListeningPorts...) {
+			ports[port] = true
+		}
+		t.ListeningPorts = sortedPorts(ports)
+	}
+	t.Sockets += other.Sockets
+	t.SocketMemory += other.SocketMemory
+	t.RxQueueLength += other.RxQueueLength
+	t.TxQueueLength += other.TxQueueLength
+}
+
+func (u *UDPNetworkStats) add(other *UDPNetworkStats) {
+	u.Sockets += other.Sockets
+	u.SocketMemory += other.SocketMemory
+	u.RxQueueLength += other.RxQueueLength
+	u.TxQueueLength += other.TxQueueLength
+}
@@ -0,0 +1,133 @@
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadNetworkStats(t *testing.T) {
+	// Given
+	procDir := t.TempDir()
+	writeSockstat(t, procDir, 10, 5, 2)
+	writeSockstat(t, procDir, 11, 5, 2)
+	writeSockstat(t, procDir, 20, 100, 30)
+	writeSockstat(t, procDir, 30, 7, 3)
+	writeSockstat(t, procDir, 31, 7, 3)
+	processes := []*ProcessInfo{
+		{PID: 10, NetNS: 4026532000},
+		{PID: 11, NetNS: 4026532000},
+		{PID: 20, NetNS: 4026531840},
+		{PID: 30},
+		{PID: 31},
+	}
+
+	// When
+	networkStats := readNetworkStats(procDir, processes, newProcessSnapshot(procDir, processes, 4026531840, 1))
+
+	// Then
+	assert.Equal(t, uint64(112), networkStats.TCPStats.Sockets, "Each namespace should only be counted once.")
+	assert.Equal(t, uint64(35), networkStats.UDPStats.Sockets)
+	assert.Equal(t, uint64(6*SocketPageSizeInBytes), networkStats.TCPStats.SocketMemory)
+	assert.True(t, networkStats.SharesHostNamespace)
+	assert.Len(t, networkStats.Namespaces, 2)
+	assert.Equal(t, uint64(4026531840), networkStats.Namespaces[0].Inode)
+	assert.True(t, networkStats.Namespaces[0].HostNamespace)
+	assert.Equal(t, uint64(2), networkStats.Namespaces[1].NumProcesses)
+	assert.Equal(t, uint64(5), networkStats.Namespaces[1].TCPStats.Sockets)
+	assert.Equal(t, uint64(2), networkStats.UnknownNamespaceProcesses(),
+		"Processes whose namespace could not be read should be counted rather than skipped.")
+	assert.Equal(t, uint64(7), networkStats.UnknownNamespace.TCPStats.Sockets)
+}
+
+func TestReadTCPStates(t *testing.T) {
+	// Given
+	procDir := t.TempDir()
+	writeSockstat(t, procDir, 10, 5, 2)
+	writeNetTCP(t, procDir, 10, "tcp",
+		"0100007F:1F90 00000000:0000 0A 100",
+		"0100007F:1F90 0100007F:D431 01 101",
+		"0100007F:1F90 0100007F:D432 08 102",
+		"0100007F:1F90 0100007F:D433 06 0",
+		"0100007F:0016 00000000:0000 0A 200")
+	writeNetTCP(t, procDir, 10, "tcp6",
+		"00000000000000000000000000000000:01BB 00000000000000000000000000000000:0000 0A 103")
+	processes := []*ProcessInfo{
+		{PID: 10, Cgroup: "/a.service", NetNS: 4026532000, SocketInodes: []uint64{100, 101, 102, 103}},
+		{PID: 20, Cgroup: "/b.service", NetNS: 4026532000, SocketInodes: []uint64{200}},
+	}
+	snapshot := newProcessSnapshot(procDir, processes, 4026531840, 1)
+
+	// When
+	networkStats := readNetworkStats(procDir, snapshot.Processes("/a.service"), snapshot)
+	otherNetworkStats := readNetworkStats(procDir, snapshot.Processes("/b.service"), snapshot)
+
+	// Then
+	assert.Len(t, snapshot.tcpTables, 1, "The sockets of a namespace should only be read once.")
+	assert.Equal(t, map[string]uint64{"ESTABLISHED": 1, "TIME_WAIT": 1, "CLOSE_WAIT": 1, "LISTEN": 2},
+		networkStats.TCPStats.States, "Sockets of other processes should not be counted.")
+	assert.Equal(t, []uint64{443, 8080}, networkStats.TCPStats.ListeningPorts)
+	assert.Equal(t, "ESTABLISHED 1, TIME_WAIT 1, CLOSE_WAIT 1, LISTEN 2", formatTCPStates(networkStats.TCPStats.States))
+	assert.Equal(t, []uint64{22}, otherNetworkStats.TCPStats.ListeningPorts)
+}
+
+// writeNetTCP writes a /proc/<pid>/net/tcp table with a socket for each of the given "local remote st inode" lines.
+func writeNetTCP(t *testing.T, procDir string, pid int, name string, sockets ...string) {
+	table := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	for i, socket := range sockets {
+		var local, remote, st, inode string
+		_, err := fmt.Sscan(socket, &local, &remote, &st, &inode)
+		assert.NoError(t, err)
+		table += fmt.Sprintf("%4d: %s %s %s 00000000:00000000 00:00000000 00000000  1000        0 %s 1 0 100 0 0 10 0\n",
+			i, local, remote, st, inode)
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(procDir, fmt.Sprintf("%d", pid), "net", name), []byte(table), 0644))
+}
+
+func writeSockstat(t *testing.T, procDir string, pid int, tcpSockets int, udpSockets int) {
+	netDir := filepath.Join(procDir, fmt.Sprintf("%d", pid), "net")
+	assert.NoError(t, os.MkdirAll(netDir, 0755))
+	sockstat := fmt.Sprintf("sockets: used 200\nTCP: inuse %d orphan 0 tw 0 alloc 10 mem 2\nUDP: inuse %d mem 1\n",
+		tcpSockets, udpSockets)
+	assert.NoError(t, os.WriteFile(filepath.Join(netDir, "sockstat"), []byte(sockstat), 0644))
+}
+
+func TestReadNetworkInterfaceStats(t *testing.T) {
+	// Given
+	procDir := t.TempDir()
+	writeSockstat(t, procDir, 10, 5, 2)
+	writeNetDev(t, procDir, 10, 1000, 100)
+	processes := []*ProcessInfo{{PID: 10, NetNS: 4026532000}}
+	counters := common.NewCounterStore()
+	first := readNetworkStats(procDir, processes, newProcessSnapshot(procDir, processes, 4026531840, 1))
+	sample := common.NewCounterSample(0, 1)
+	first.addCounters(sample)
+	counters.Set("/test", sample)
+	writeNetDev(t, procDir, 10, 3000, 600)
+
+	// When
+	networkStats := readNetworkStats(procDir, processes, newProcessSnapshot(procDir, processes, 4026531840, 1))
+	sample = common.NewCounterSample(2*1e6, 1)
+	networkStats.addCounters(sample)
+	networkStats.withRates(counters.Update("/test", sample))
+
+	// Then
+	assert.Len(t, networkStats.Namespaces[0].Interfaces, 2)
+	total := networkStats.InterfaceTotal()
+	assert.Equal(t, uint64(3000), total.RxBytes, "Loopback traffic should be left out of the total.")
+	assert.Equal(t, uint64(600), total.TxBytes)
+	assert.Equal(t, 1000.0, total.RxBytesPerSec)
+	assert.Equal(t, 250.0, total.TxBytesPerSec)
+}
+
+func writeNetDev(t *testing.T, procDir string, pid int, rxBytes int, txBytes int) {
+	netDev := "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n" +
+		fmt.Sprintf("    lo: 500 5 0 0 0 0 0 0 500 5 0 0 0 0 0 0\n  eth0: %d 10 1 2 0 0 0 0 %d 8 0 1 0 0 0 0\n",
+			rxBytes, txBytes)
+	assert.NoError(t, os.WriteFile(filepath.Join(procDir, fmt.Sprintf("%d", pid), "net", "dev"), []byte(netDev), 0644))
+}
@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	CPUPressureFile    = "cpu.pressure"
+	MemoryPressureFile = "memory.pressure"
+	IOPressureFile     = "io.pressure"
+)
+
+// readPressureStats parses a PSI file such as cpu.pressure, which has the following format:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressureStats(cgroupDir string, filename string) (*PressureStats, error) {
+	f, err := os.Open(filepath.Join(cgroupDir, filename))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pressureStats := &PressureStats{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		data, err := parsePressureData(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", filename, err)
+		}
+		switch fields[0] {
+		case "some":
+			pressureStats.Some = data
+		case "full":
+			pressureStats.Full = data
+		}
+	}
+	return pressureStats, scanner.Err()
+}
+
+func parsePressureData(fields []string) (*PressureData, error) {
+	data := &PressureData{}
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return nil, fmt.Errorf("malformed field %q", field)
+		}
+		var err error
+		switch key {
+		case "avg10":
+			data.Avg10, err = strconv.ParseFloat(value, 64)
+		case "avg60":
+			data.Avg60, err = strconv.ParseFloat(value, 64)
+		case "avg300":
+			data.Avg300, err = strconv.ParseFloat(value, 64)
+		case "total":
+			data.Total, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
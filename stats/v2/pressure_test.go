@@ -0,0 +1,38 @@
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPressureStats(t *testing.T) {
+	// Given
+	cgroupDir := t.TempDir()
+	content := "some avg10=1.50 avg60=0.25 avg300=0.00 total=12345\n" +
+		"full avg10=0.50 avg60=0.00 avg300=0.00 total=678\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupDir, MemoryPressureFile), []byte(content), 0644))
+
+	// When
+	pressure, err := readPressureStats(cgroupDir, MemoryPressureFile)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, &PressureStats{
+		Some: &PressureData{Avg10: 1.5, Avg60: 0.25, Avg300: 0, Total: 12345},
+		Full: &PressureData{Avg10: 0.5, Avg60: 0, Avg300: 0, Total: 678},
+	}, pressure)
+}
+
+func TestReadPressureStats_MissingFile(t *testing.T) {
+	// Given
+	cgroupDir := t.TempDir()
+
+	// When
+	_, err := readPressureStats(cgroupDir, CPUPressureFile)
+
+	// Then
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package v2
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const ProcDir = "/proc"
+
+// ProcessInfo is what a scan of /proc records about a single process.
+type ProcessInfo struct {
+	PID int
+	// Cgroup is the path of the cgroup of the process, relative to the root of the cgroup v2 hierarchy.
+	Cgroup string
+	// NumFDs is the number of open file descriptors of the process.
+	NumFDs uint64
+	// FDs is the number of open file descriptors of the process of each type.
+	FDs FDCounts
+	// MaxFDs is the soft RLIMIT_NOFILE of the process, or zero if it could not be read or is unlimited.
+	MaxFDs uint64
+	// NetNS is the inode of the network namespace of the process, or zero if it could not be read.
+	NetNS uint64
+	// SocketInodes are the inodes of the sockets the process has open, which match the inode column of
+	// /proc/net/tcp.
+	SocketInodes []uint64
+}
+
+// ProcessSnapshot contains the processes of the sampled cgroups, indexed by cgroup. A snapshot is taken once per sample
+// and shared by every cgroup of the sample, rather than enumerating the processes of each cgroup separately.
+type ProcessSnapshot struct {
+	// byCgroup contains the processes of each cgroup and its descendants, keyed by the path of the cgroup.
+	byCgroup map[string][]*ProcessInfo
+	// tcpTables contains the TCP sockets of each network namespace used by the processes, keyed by the inode of the
+	// namespace.
+	tcpTables map[uint64]*tcpSocketTable
+	// HostNetNS is the inode of the network namespace of the init process, or zero if it could not be read.
+	HostNetNS uint64
+}
+
+// errNotRequested is returned for processes outside of the cgroups being sampled, which are skipped.
+var errNotRequested = errors.New("process is not in a requested cgroup")
+
+// scanProcesses reads the cgroup of every process in the given proc directory, and the open file descriptors, limits
+// and network namespace of the processes in the given cgroups and their descendants, with at most concurrency
+// processes being read at the same time. Processes which exit during the scan are skipped.
+func scanProcesses(procDir string, cgroupPaths []string, concurrency int) *ProcessSnapshot {
+	hostNetNS := readNetNS(filepath.Join(procDir, "1"))
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return newProcessSnapshot(procDir, nil, hostNetNS, concurrency)
+	}
+	var pidDirs []string
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pidDirs = append(pidDirs, filepath.Join(procDir, entry.Name()))
+		}
+	}
+
+	requested := make(map[string]bool, len(cgroupPaths))
+	for _, cgroupPath := range cgroupPaths {
+		requested[cleanCgroupPath(cgroupPath)] = true
+	}
+	var processes []*ProcessInfo
+	results, errs := common.CollectConcurrently(pidDirs, concurrency, func(pidDir string) (*ProcessInfo, error) {
+		return readProcessInfo(pidDir, requested)
+	})
+	for i, process := range results {
+		if errs[i] == nil {
+			processes = append(processes, process)
+		}
+	}
+	return newProcessSnapshot(procDir, processes, hostNetNS, concurrency)
+}
+
+// newProcessSnapshot indexes the given processes by their cgroup and each of its ancestors, and reads the TCP sockets of each of their network
+// namespaces once, through the first process found in the namespace.
+func newProcessSnapshot(procDir string, processes []*ProcessInfo, hostNetNS uint64, concurrency int) *ProcessSnapshot {
+	snapshot := &ProcessSnapshot{
+		byCgroup:  map[string][]*ProcessInfo{},
+		tcpTables: map[uint64]*tcpSocketTable{},
+		HostNetNS: hostNetNS,
+	}
+	var netNSs []uint64
+	var netNSDirs []string
+	for _, process := range processes {
+		for _, cgroup := range cgroupAncestors(process.Cgroup) {
+			snapshot.byCgroup[cgroup] = append(snapshot.byCgroup[cgroup], process)
+		}
+		if _, ok := snapshot.tcpTables[process.NetNS]; process.NetNS != 0 && !ok {
+			snapshot.tcpTables[process.NetNS] = nil
+			netNSs = append(netNSs, process.NetNS)
+			netNSDirs = append(netNSDirs, filepath.Join(procDir, strconv.Itoa(process.PID)))
+		}
+	}
+	tables, errs := common.CollectConcurrently(netNSDirs, concurrency, readTCPSocketTable)
+	for i, table := range tables {
+		if errs[i] == nil {
+			snapshot.tcpTables[netNSs[i]] = table
+		}
+	}
+	return snapshot
+}
+
+// readProcessInfo reads the cgroup of a process first, so that the rest of its files are only read if it is in one of
+// the requested cgroups or their descendants.
+func readProcessInfo(pidDir string, requested map[string]bool) (*ProcessInfo, error) {
+	pid, err := strconv.Atoi(filepath.Base(pidDir))
+	if err != nil {
+		return nil, err
+	}
+	cgroup, err := readProcessCgroup(pidDir)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(cgroupAncestors(cgroup), func(ancestor string) bool {
+		return requested[ancestor]
+	}) {
+		return nil, errNotRequested
+	}
+	process := &ProcessInfo{
+		PID:    pid,
+		Cgroup: cgroup,
+	}
+	// The file descriptors and namespaces of processes owned by other users cannot be read without privileges.
+	fdDir := filepath.Join(pidDir, "fd")
+	if fds, err := os.ReadDir(fdDir); err == nil {
+		process.NumFDs = uint64(len(fds))
+		for _, fd := range fds {
+			// Descriptors closed since the directory was read are counted as other descriptors.
+			target, _ := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			process.FDs.add(target)
+			// Sockets are linked as "socket:[<inode>]".
+			if inode, found := strings.CutPrefix(target, "socket:["); found {
+				if socketInode, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64); err == nil {
+					process.SocketInodes = append(process.SocketInodes, socketInode)
+				}
+			}
+		}
+	}
+	process.MaxFDs = readMaxFDs(pidDir)
+	process.NetNS = readNetNS(pidDir)
+	return process, nil
+}
+
+// readNetNS returns the inode of the network namespace of the process with the given proc directory, or zero if it
+// could not be read.
+func readNetNS(pidDir string) uint64 {
+	info, err := os.Stat(filepath.Join(pidDir, "ns", "net"))
+	if err != nil {
+		return 0
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return stat.Ino
+	}
+	return 0
+}
+
+// readMaxFDs returns the soft limit of the "Max open files" line of /proc/<pid>/limits, or zero if it could not be
+// read or is unlimited.
+func readMaxFDs(pidDir string) uint64 {
+	f, err := os.Open(filepath.Join(pidDir, "limits"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Such as "Max open files            1024                 524288               files".
+		if limits, found := strings.CutPrefix(scanner.Text(), "Max open files"); found {
+			fields := strings.Fields(limits)
+			if len(fields) == 0 {
+				return 0
+			}
+			maxFDs, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return maxFDs
+		}
+	}
+	return 0
+}
+
+// add counts a file descriptor by the target of its /proc/<pid>/fd link.
+func (f *FDCounts) add(target string) {
+	switch {
+	case strings.HasPrefix(target, "/"):
+		f.Files++
+	case strings.HasPrefix(target, "socket:"):
+		f.Sockets++
+	case strings.HasPrefix(target, "pipe:"):
+		f.Pipes++
+	case strings.HasPrefix(target, "anon_inode:"):
+		f.AnonInodes++
+	default:
+		f.Other++
+	}
+}
+
+func (f *FDCounts) addCounts(other FDCounts) {
+	f.Files += other.Files
+	f.Sockets += other.Sockets
+	f.Pipes += other.Pipes
+	f.AnonInodes += other.AnonInodes
+	f.Other += other.Other
+}
+
+// UsagePercent returns the number of open file descriptors as a percentage of the limit.
+func (u *ProcessFDUsage) UsagePercent() float64 {
+	if u == nil || u.MaxFDs == 0 {
+		return 0.0
+	}
+	return float64(u.NumFDs) / float64(u.MaxFDs) * 100.0
+}
+
+// readProcessCgroup returns the cgroup v2 path from /proc/<pid>/cgroup, which is on the line with the hierarchy ID 0,
+// such as "0::/system.slice/sshd.service".
+func readProcessCgroup(pidDir string) (string, error) {
+	f, err := os.Open(filepath.Join(pidDir, "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if cgroup, found := strings.CutPrefix(scanner.Text(), "0::"); found {
+			return cgroup, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", os.ErrNotExist
+}
+
+// Processes returns the processes in the given cgroup and its descendants.
+func (s *ProcessSnapshot) Processes(cgroupPath string) []*ProcessInfo {
+	return s.byCgroup[cleanCgroupPath(cgroupPath)]
+}
+
+// cgroupAncestors returns the given cgroup followed by each of its ancestors, up to the root cgroup.
+func cgroupAncestors(cgroup string) []string {
+	cgroup = cleanCgroupPath(cgroup)
+	ancestors := []string{cgroup}
+	for cgroup != "/" {
+		cgroup = path.Dir(cgroup)
+		ancestors = append(ancestors, cgroup)
+	}
+	return ancestors
+}
+
+// cleanCgroupPath returns the given cgroup path with a single leading slash and no trailing slash, such as
+// "/system.slice".
+func cleanCgroupPath(cgroupPath string) string {
+	return "/" + strings.Trim(cgroupPath, "/")
+}
@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanProcesses(t *testing.T) {
+	// Given
+	procDir := t.TempDir()
+	writeProcess(t, procDir, "1", "0::/init.scope\n", 3)
+	writeProcess(t, procDir, "20", "0::/system.slice/a.service\n", 2)
+	writeProcess(t, procDir, "21", "0::/system.slice/a.service/worker\n", 1)
+	writeProcess(t, procDir, "30", "0::/system.slice/ab.service\n", 5)
+	assert.NoError(t, os.Symlink("socket:[4242]", filepath.Join(procDir, "30", "fd", "9")))
+	assert.NoError(t, os.Symlink("/var/log/syslog", filepath.Join(procDir, "30", "fd", "10")))
+	assert.NoError(t, os.Symlink("anon_inode:[eventpoll]", filepath.Join(procDir, "30", "fd", "11")))
+	limits := "Limit                     Soft Limit           Hard Limit           Units     \n" +
+		"Max open files            1024                 524288               files     \n"
+	assert.NoError(t, os.WriteFile(filepath.Join(procDir, "30", "limits"), []byte(limits), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(procDir, "sys"), 0755))
+
+	// When
+	snapshot := scanProcesses(procDir, []string{"/"}, 2)
+
+	// Then
+	assert.Equal(t, []int{20, 21}, pids(snapshot.Processes("/system.slice/a.service")))
+	assert.Equal(t, []int{20, 21, 30}, pids(snapshot.Processes("/system.slice")))
+	assert.Equal(t, []int{1, 20, 21, 30}, pids(snapshot.Processes("/")))
+	numFDs := uint64(0)
+	for _, process := range snapshot.Processes("/system.slice/a.service/") {
+		numFDs += process.NumFDs
+	}
+	assert.Equal(t, uint64(3), numFDs)
+	process := snapshot.Processes("/system.slice/ab.service")[0]
+	assert.Equal(t, []uint64{4242}, process.SocketInodes)
+	assert.Equal(t, FDCounts{Files: 1, Sockets: 1, AnonInodes: 1, Other: 5}, process.FDs)
+	assert.Equal(t, uint64(1024), process.MaxFDs)
+	assert.Equal(t, uint64(0), snapshot.Processes("/init.scope")[0].MaxFDs, "A missing limit should be unknown.")
+}
+
+func TestScanProcesses_OnlyRequestedCgroups(t *testing.T) {
+	// Given
+	procDir := t.TempDir()
+	writeProcess(t, procDir, "1", "0::/init.scope\n", 3)
+	writeProcess(t, procDir, "20", "0::/system.slice/a.service\n", 2)
+	writeProcess(t, procDir, "21", "0::/system.slice/a.service/worker\n", 1)
+	writeProcess(t, procDir, "30", "0::/system.slice/ab.service\n", 5)
+	assert.NoError(t, os.MkdirAll(filepath.Join(procDir, "1", "ns"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(procDir, "1", "ns", "net"), nil, 0644))
+
+	// When
+	snapshot := scanProcesses(procDir, []string{"/system.slice/a.service"}, 2)
+
+	// Then
+	assert.Equal(t, []int{20, 21}, pids(snapshot.Processes("/")), "Processes of other cgroups should be skipped.")
+	assert.Equal(t, readNetNS(filepath.Join(procDir, "1")), snapshot.HostNetNS,
+		"The host namespace should be read even if the init process is not in a requested cgroup.")
+	assert.NotZero(t, snapshot.HostNetNS)
+}
+
+func writeProcess(t *testing.T, procDir string, pid string, cgroup string, numFDs int) {
+	pidDir := filepath.Join(procDir, pid)
+	assert.NoError(t, os.MkdirAll(filepath.Join(pidDir, "fd"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "cgroup"), []byte(cgroup), 0644))
+	for fd := 0; fd < numFDs; fd++ {
+		assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "fd", string(rune('0'+fd))), nil, 0644))
+	}
+}
+
+func pids(processes []*ProcessInfo) []int {
+	var pids []int
+	for _, process := range processes {
+		pids = append(pids, process.PID)
+	}
+	sort.Ints(pids)
+	return pids
+}
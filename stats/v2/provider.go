@@ -1,11 +1,12 @@
 package v2
 
 import (
+	"errors"
 	"fmt"
 	"github.com/containerd/cgroups/v3/cgroup2"
 	"github.com/containerd/cgroups/v3/cgroup2/stats"
-	"github.com/prometheus/procfs"
 	"github.com/strategicpause/cgstat/stats/common"
+	"path/filepath"
 	"time"
 )
 
@@ -16,14 +17,18 @@ const (
 )
 
 type CgroupStatsProvider struct {
-	commonProvider               *common.CommonCgroupStatsProvider
-	previousCPUStatsByCgroupPath map[string]*CPUStats
+	config         *common.ProviderConfig
+	commonProvider *common.CommonCgroupStatsProvider
+	deviceResolver *common.DeviceResolver
+	counters       *common.CounterStore
 }
 
-func NewCgroupStatsProvider() common.CgroupStatsProvider {
+func NewCgroupStatsProvider(opts ...common.ProviderOpt) common.CgroupStatsProvider {
 	return &CgroupStatsProvider{
-		commonProvider:               common.NewCommonCgroupStatsProvider(CgroupPrefix),
-		previousCPUStatsByCgroupPath: map[string]*CPUStats{},
+		config:         common.NewProviderConfig(opts...),
+		deviceResolver: common.NewDeviceResolver(),
+		commonProvider: common.NewCommonCgroupStatsProvider(CgroupPrefix),
+		counters:       common.NewCounterStore(),
 	}
 }
 
@@ -33,7 +38,11 @@ func (c *CgroupStatsProvider) ListCgroupsByPrefix(cgroupPrefix string) []string
 
 func (c *CgroupStatsProvider) GetCgroupStatsByPrefix(prefix string) (common.CgroupStatsCollection, error) {
 	paths := c.ListCgroupsByPrefix(prefix)
-	return c.getCgroupStatsByPath(paths)
+	collection, err := c.getCgroupStatsByPath(paths)
+	// Samples of a single cgroup by name, such as those of the detail view of the interactive view, keep the counters
+	// of the other cgroups.
+	c.counters.Retain(paths)
+	return collection, err
 }
 
 func (c *CgroupStatsProvider) GetCgroupStatsByName(name string) (common.CgroupStatsCollection, error) {
@@ -42,21 +51,41 @@ func (c *CgroupStatsProvider) GetCgroupStatsByName(name string) (common.CgroupSt
 	return c.getCgroupStatsByPath(paths)
 }
 
+func (c *CgroupStatsProvider) SetPreviousSample(collection common.CgroupStatsCollection) error {
+	previous, ok := collection.(common.Collection[*CgroupStats])
+	if !ok {
+		return errors.New("previous sample is not a cgroup v2 sample")
+	}
+	for _, cgroupStats := range previous.Stats {
+		c.counters.Set(cgroupStats.Name, cgroupStats.counterSample())
+	}
+	return nil
+}
+
 func (c *CgroupStatsProvider) getCgroupStatsByPath(cgroupPaths []string) (common.CgroupStatsCollection, error) {
 	var statsCollection []*CgroupStats
 
-	for _, cgroupPath := range cgroupPaths {
-		cgroupStats, err := c.getStatsByCgroupPath(cgroupPath)
+	// Every cgroup of a sample shares the same timestamp, so that rates cover the same interval.
+	sampleTime := time.Now()
+	processes := scanProcesses(ProcDir, cgroupPaths, c.config.Concurrency)
+	results, errs := common.CollectConcurrently(cgroupPaths, c.config.Concurrency,
+		func(cgroupPath string) (*CgroupStats, error) {
+			return c.getStatsByCgroupPath(cgroupPath, sampleTime, processes)
+		})
+	for i, cgroupStats := range results {
 		// TODO - Add a debug mode for logging these kinds of errors. Otherwise let's skip for now since it will add noise.
-		if err == nil {
+		if errs[i] == nil {
 			statsCollection = append(statsCollection, cgroupStats)
 		}
 	}
 
-	return NewCollection(statsCollection), nil
+	collection := newCollection(statsCollection)
+	collection.Timestamp = sampleTime
+	return collection, nil
 }
 
-func (c *CgroupStatsProvider) getStatsByCgroupPath(cgroupPath string) (*CgroupStats, error) {
+func (c *CgroupStatsProvider) getStatsByCgroupPath(cgroupPath string, sampleTime time.Time,
+	processes *ProcessSnapshot) (*CgroupStats, error) {
 	mgr, err := cgroup2.Load(cgroupPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not load cgroup %s: %w", cgroupPath, err)
@@ -66,40 +95,39 @@ func (c *CgroupStatsProvider) getStatsByCgroupPath(cgroupPath string) (*CgroupSt
 		return nil, err
 	}
 
-	previousCPUStats := c.previousCPUStatsByCgroupPath[cgroupPath]
-
 	cgroupStats := NewCgroupStat(cgroupPath,
-		c.withCPU(metrics.GetCPU(), previousCPUStats),
+		c.withID(cgroupPath),
+		c.withCPU(cgroupPath, metrics.GetCPU(), sampleTime),
 		c.withPids(metrics.GetPids()),
-		c.withProcStats(mgr),
+		c.withProcStats(processes.Processes(cgroupPath)),
 		c.withMemory(metrics.GetMemory()),
 		c.withMemoryEvents(metrics.GetMemoryEvents()),
-		c.withNetwork(mgr),
+		c.withNetwork(processes.Processes(cgroupPath), processes),
+		c.withPressure(cgroupPath),
+		c.withIO(cgroupPath, sampleTime),
 	)
-
-	// Use the current CPU stats as the previous for this cgroup
-	c.previousCPUStatsByCgroupPath[cgroupPath] = cgroupStats.CPU
+	cgroupStats.withRates(c.counters.Update(cgroupPath, cgroupStats.counterSample()))
 
 	return cgroupStats, nil
 }
 
-func (c *CgroupStatsProvider) withCPU(cpu *stats.CPUStat, prevCpu *CPUStats) CgroupStatsOpt {
+func (c *CgroupStatsProvider) withID(cgroupPath string) CgroupStatsOpt {
+	return func(cgroupStats *CgroupStats) {
+		cgroupStats.ID = common.CgroupID(filepath.Join(CgroupPrefix, cgroupPath))
+	}
+}
+
+func (c *CgroupStatsProvider) withCPU(cgroupPath string, cpu *stats.CPUStat, sampleTime time.Time) CgroupStatsOpt {
 	return func(cgroupStats *CgroupStats) {
 		cgroupStats.CPU = &CPUStats{
-			SystemTime:          time.Now().UnixMicro(),
+			SystemTime:          sampleTime.UnixMicro(),
 			NumThrottledPeriods: cpu.GetNrThrottled(),
 			NumRunnablePeriods:  cpu.GetNrPeriods(),
 			UsageInUsec:         cpu.GetUsageUsec(),
 			SystemTimeInUsec:    cpu.GetSystemUsec(),
 			UserTimeInUsec:      cpu.GetUserUsec(),
 			ThrottledTimeInUsec: cpu.GetThrottledUsec(),
-		}
-		if prevCpu == nil {
-			cgroupStats.CPU.Utilization = 0.0
-		} else {
-			cpuUsageDelta := float64(cgroupStats.CPU.UsageInUsec - prevCpu.UsageInUsec)
-			systemTimeDelta := float64(cgroupStats.CPU.SystemTime - prevCpu.SystemTime)
-			cgroupStats.CPU.Utilization = (cpuUsageDelta / systemTimeDelta) * 100.0
+			Limit:               readCPULimit(CgroupPrefix, cgroupPath),
 		}
 	}
 }
@@ -183,19 +211,20 @@ func (c *CgroupStatsProvider) withMemoryEvents(memoryEvents *stats.MemoryEvents)
 	}
 }
 
-func (c *CgroupStatsProvider) withProcStats(mgr *cgroup2.Manager) CgroupStatsOpt {
+func (c *CgroupStatsProvider) withProcStats(processes []*ProcessInfo) CgroupStatsOpt {
 	return func(cgroupStats *CgroupStats) {
-		pids, err := mgr.Procs(true)
-		if err != nil {
-			return
-		}
-
 		procStats := ProcStats{}
-		// For each PID in the cgroup, determine the number of open file descriptors it has.
-		for _, pid := range pids {
-			if proc, err := procfs.NewProc(int(pid)); err == nil {
-				fds, _ := proc.FileDescriptorsLen()
-				procStats.NumFD += uint64(fds)
+		for _, process := range processes {
+			procStats.NumFD += process.NumFDs
+			procStats.FDs.addCounts(process.FDs)
+			if process.MaxFDs == 0 {
+				continue
+			}
+			usage := &ProcessFDUsage{PID: process.PID, NumFDs: process.NumFDs, MaxFDs: process.MaxFDs}
+			closest := procStats.ClosestToFDLimit
+			if closest == nil || usage.UsagePercent() > closest.UsagePercent() ||
+				(usage.UsagePercent() == closest.UsagePercent() && usage.PID < closest.PID) {
+				procStats.ClosestToFDLimit = usage
 			}
 		}
 
@@ -203,46 +232,47 @@ func (c *CgroupStatsProvider) withProcStats(mgr *cgroup2.Manager) CgroupStatsOpt
 	}
 }
 
-func (c *CgroupStatsProvider) withNetwork(mgr *cgroup2.Manager) CgroupStatsOpt {
+func (c *CgroupStatsProvider) withNetwork(processes []*ProcessInfo, snapshot *ProcessSnapshot) CgroupStatsOpt {
+	return func(cgroupStats *CgroupStats) {
+		cgroupStats.Network = readNetworkStats(ProcDir, processes, snapshot)
+	}
+}
+
+func (c *CgroupStatsProvider) withPressure(cgroupPath string) CgroupStatsOpt {
 	return func(cgroupStats *CgroupStats) {
-		pids, err := mgr.Procs(true)
+		cgroupDir := filepath.Join(CgroupPrefix, cgroupPath)
+		pressure := &PressureStallStats{}
+		// PSI files are missing when the kernel is built or booted without PSI support, in which case the given
+		// resource is left empty.
+		if cpuPressure, err := readPressureStats(cgroupDir, CPUPressureFile); err == nil {
+			pressure.CPU = cpuPressure
+		}
+		if memoryPressure, err := readPressureStats(cgroupDir, MemoryPressureFile); err == nil {
+			pressure.Memory = memoryPressure
+		}
+		if ioPressure, err := readPressureStats(cgroupDir, IOPressureFile); err == nil {
+			pressure.IO = ioPressure
+		}
+		cgroupStats.Pressure = pressure
+	}
+}
+
+func (c *CgroupStatsProvider) withIO(cgroupPath string, sampleTime time.Time) CgroupStatsOpt {
+	return func(cgroupStats *CgroupStats) {
+		devicesByNumber, err := readIOStats(filepath.Join(CgroupPrefix, cgroupPath))
 		if err != nil {
 			return
 		}
-
-		tcpStats := &TCPNetworkStats{}
-		udpStats := &UDPNetworkStats{}
-
-		for _, pid := range pids {
-			procPath := fmt.Sprintf("/proc/%d", pid)
-			if fs, err := procfs.NewFS(procPath); err == nil {
-				if tcpSummary, err := fs.NetTCPSummary(); err == nil {
-					tcpStats.TxQueueLength += tcpSummary.TxQueueLength
-					tcpStats.RxQueueLength += tcpSummary.RxQueueLength
-				}
-				if udpSummary, err := fs.NetUDPSummary(); err == nil {
-					udpStats.TxQueueLength += udpSummary.TxQueueLength
-					udpStats.RxQueueLength += udpSummary.RxQueueLength
-				}
-				if netSockStat, err := fs.NetSockstat(); err == nil {
-					for _, protocol := range netSockStat.Protocols {
-						if protocol.Protocol == "TCP" {
-							tcpStats.Sockets += uint64(protocol.InUse)
-							// The Mem value is reported in pages, so we need to convert pages to bytes in order to
-							// determine how much memory is being used.
-							tcpStats.SocketMemory += uint64(*protocol.Mem * SocketPageSizeInBytes)
-						} else if protocol.Protocol == "UDP" {
-							udpStats.Sockets += uint64(protocol.InUse)
-							udpStats.SocketMemory += uint64(*protocol.Mem * SocketPageSizeInBytes)
-						}
-					}
-				}
+		devices := map[string]*IODeviceStats{}
+		for _, device := range devicesByNumber {
+			deviceName := c.deviceResolver.Resolve(device.Major, device.Minor)
+			if c.config.IncludesDevice(device.Major, device.Minor, deviceName) {
+				devices[deviceName] = device
 			}
 		}
-
-		cgroupStats.Network = &NetworkStats{
-			TCPStats: tcpStats,
-			UDPStats: udpStats,
+		cgroupStats.IO = &IOStats{
+			SystemTime: sampleTime.UnixMicro(),
+			Devices:    devices,
 		}
 	}
 }
@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRates(t *testing.T) {
+	// Given
+	store := common.NewCounterStore()
+	previous := &CgroupStats{ID: 1, CPU: &CPUStats{UsageInUsec: 1_000_000, SystemTime: 1_000_000},
+		Memory: &MemoryStats{PageCache: &PageCacheStats{Fault: 100}}}
+	store.Update("/a.service", previous.counterSample())
+
+	t.Run("Compute utilization and rates from the previous sample.", func(t *testing.T) {
+		cgroupStats := &CgroupStats{ID: 1, CPU: &CPUStats{UsageInUsec: 1_500_000, SystemTime: 2_000_000},
+			Memory: &MemoryStats{PageCache: &PageCacheStats{Fault: 300}}}
+
+		cgroupStats.withRates(store.Update("/a.service", cgroupStats.counterSample()))
+
+		assert.Equal(t, 50.0, cgroupStats.CPU.Utilization)
+		assert.Equal(t, 200.0, cgroupStats.Rates[common.PageFaultsCounter])
+	})
+
+	t.Run("Ignore a previous sample of a recreated cgroup.", func(t *testing.T) {
+		cgroupStats := &CgroupStats{ID: 2, CPU: &CPUStats{UsageInUsec: 2_000_000, SystemTime: 3_000_000}}
+
+		cgroupStats.withRates(store.Update("/a.service", cgroupStats.counterSample()))
+
+		assert.Equal(t, 0.0, cgroupStats.CPU.Utilization)
+		assert.Nil(t, cgroupStats.Rates)
+	})
+}
+
+func TestSetPreviousSample(t *testing.T) {
+	// Given
+	provider := NewCgroupStatsProvider().(*CgroupStatsProvider)
+	previous := &CgroupStats{Name: "/a.service", CPU: &CPUStats{UsageInUsec: 1000, SystemTime: 1_000_000}}
+	current := &CgroupStats{Name: "/a.service", CPU: &CPUStats{UsageInUsec: 501_000, SystemTime: 2_000_000}}
+
+	// When
+	err := provider.SetPreviousSample(NewCollection([]*CgroupStats{previous}))
+
+	// Then
+	assert.NoError(t, err)
+	deltas := provider.counters.Update("/a.service", current.counterSample())
+	assert.Equal(t, 50.0, common.UtilizationFromDeltas(deltas))
+	assert.Error(t, provider.SetPreviousSample(common.Collection[*common.Rollup]{}))
+}
+
+func TestWithProcStats(t *testing.T) {
+	// Given
+	provider := NewCgroupStatsProvider().(*CgroupStatsProvider)
+	processes := []*ProcessInfo{
+		{PID: 10, NumFDs: 900, MaxFDs: 65536, FDs: FDCounts{Files: 890, Sockets: 10}},
+		{PID: 20, NumFDs: 950, MaxFDs: 1024, FDs: FDCounts{Sockets: 900, Pipes: 50}},
+		{PID: 30, NumFDs: 3},
+	}
+	cgroupStats := &CgroupStats{}
+
+	// When
+	provider.withProcStats(processes)(cgroupStats)
+
+	// Then
+	assert.Equal(t, uint64(1853), cgroupStats.ProcStats.NumFD)
+	assert.Equal(t, FDCounts{Files: 890, Sockets: 910, Pipes: 50}, cgroupStats.ProcStats.FDs)
+	assert.Equal(t, 20, cgroupStats.ProcStats.ClosestToFDLimit.PID,
+		"The process closest to its own limit should be reported, rather than the one with the most FDs.")
+	assert.InDelta(t, 92.77, cgroupStats.ProcStats.ClosestToFDLimit.UsagePercent(), 0.01)
+}
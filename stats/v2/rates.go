@@ -0,0 +1,87 @@
+package v2
+
+import "github.com/strategicpause/cgstat/stats/common"
+
+// counterSample returns every monotonically increasing counter of the cgroup.
+func (c *CgroupStats) counterSample() *common.CounterSample {
+	var systemTime int64
+	if c.CPU != nil {
+		systemTime = c.CPU.SystemTime
+	}
+	sample := common.NewCounterSample(systemTime, c.ID)
+	if c.CPU != nil {
+		sample.Counters[common.CPUUsageCounter] = c.CPU.UsageInUsec
+		sample.Counters["cpu.user_usec"] = c.CPU.UserTimeInUsec
+		sample.Counters["cpu.system_usec"] = c.CPU.SystemTimeInUsec
+		sample.Counters[common.ThrottledPeriodsCounter] = c.CPU.NumThrottledPeriods
+		sample.Counters[common.RunnablePeriodsCounter] = c.CPU.NumRunnablePeriods
+		sample.Counters[common.ThrottledUsecCounter] = c.CPU.ThrottledTimeInUsec
+	}
+	if c.Memory != nil {
+		if pageCache := c.Memory.PageCache; pageCache != nil {
+			sample.Counters[common.PageFaultsCounter] = pageCache.Fault
+			sample.Counters[common.MajorPageFaultsCounter] = pageCache.MajorFault
+			sample.Counters["memory.pgactivate"] = pageCache.Activate
+			sample.Counters["memory.pgdeactivate"] = pageCache.Deactivate
+			sample.Counters["memory.pglazyfree"] = pageCache.LazyFree
+			sample.Counters["memory.pglazyfreed"] = pageCache.LazyFreed
+			sample.Counters["memory.pgrefill"] = pageCache.Refill
+			sample.Counters["memory.pgscan"] = pageCache.Scan
+			sample.Counters["memory.pgsteal"] = pageCache.Steal
+		}
+		if workingset := c.Memory.Workingset; workingset != nil {
+			sample.Counters["memory.workingset_refault"] = workingset.Refault
+			sample.Counters["memory.workingset_activate"] = workingset.Activate
+			sample.Counters["memory.workingset_nodereclaim"] = workingset.Nodereclaim
+		}
+		if thp := c.Memory.TransparentHugepage; thp != nil {
+			sample.Counters["memory.thp_fault_alloc"] = thp.TransparentHugepageFaultAlloc
+			sample.Counters["memory.thp_collapse_alloc"] = thp.TransparentHugepageCollapseAlloc
+		}
+	}
+	if c.MemoryEvent != nil {
+		sample.Counters["memory.oom_events"] = c.MemoryEvent.NumOomEvents
+		sample.Counters[common.OomKillsCounter] = c.MemoryEvent.NumOomKillEvents
+		sample.Counters["memory.high_events"] = c.MemoryEvent.High
+		sample.Counters["memory.max_events"] = c.MemoryEvent.Max
+		sample.Counters["memory.low_events"] = c.MemoryEvent.Low
+	}
+	if c.Pressure != nil {
+		for i, pressure := range c.Pressure.byResource() {
+			resource := pressureCounterNames[i]
+			if some := pressure.getSome(); some != nil {
+				sample.Counters["pressure."+resource+".some_usec"] = some.Total
+			}
+			if full := pressure.getFull(); full != nil {
+				sample.Counters["pressure."+resource+".full_usec"] = full.Total
+			}
+		}
+	}
+	if c.IO != nil {
+		c.IO.addCounters(sample)
+	}
+	if c.Network != nil {
+		c.Network.addCounters(sample)
+	}
+	return sample
+}
+
+// pressureCounterNames are the names used for the stall time counters of the resources returned by
+// PressureStallStats.byResource, in order.
+var pressureCounterNames = []string{"cpu", "memory", "io"}
+
+// withRates sets the stats which are calculated from the increase of the counters since the previous sample, which
+// is nil if there was none.
+func (c *CgroupStats) withRates(deltas *common.CounterDeltas) {
+	if c.CPU != nil {
+		c.CPU.Utilization = common.UtilizationFromDeltas(deltas)
+		c.CPU.Throttling = common.NewThrottlingRate(deltas)
+	}
+	if c.IO != nil {
+		c.IO.withRates(deltas)
+	}
+	if c.Network != nil {
+		c.Network.withRates(deltas)
+	}
+	c.Rates = deltas.Rates()
+}
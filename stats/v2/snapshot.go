@@ -0,0 +1,107 @@
+package v2
+
+import (
+	"slices"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+func toSnapshot(c *CgroupStats) *common.CgroupSnapshot {
+	snapshot := &common.CgroupSnapshot{
+		Name:  c.Name,
+		Rates: c.Rates,
+	}
+	if c.CPU != nil {
+		snapshot.Timestamp = time.UnixMicro(c.CPU.SystemTime)
+		snapshot.CPU = &common.CPUSnapshot{
+			UsageUsec:        c.CPU.UsageInUsec,
+			UserUsec:         common.Uint64(c.CPU.UserTimeInUsec),
+			SystemUsec:       common.Uint64(c.CPU.SystemTimeInUsec),
+			Utilization:      c.CPU.Utilization,
+			ThrottledPeriods: c.CPU.NumThrottledPeriods,
+			TotalPeriods:     c.CPU.NumRunnablePeriods,
+			ThrottledUsec:    common.Uint64(c.CPU.ThrottledTimeInUsec),
+			Limit:            c.CPU.Limit,
+			Throttling:       c.CPU.Throttling,
+		}
+	}
+	if c.Memory != nil {
+		snapshot.Memory = &common.MemorySnapshot{
+			Usage:           c.Memory.Usage,
+			Limit:           c.Memory.UsageLimit,
+			Anon:            c.Memory.Anon.Total,
+			File:            c.Memory.Filesystem.Current,
+			Kernel:          c.Memory.Kernel.Slab + c.Memory.Kernel.Stack,
+			Swap:            common.Uint64(c.Memory.Swap.Usage),
+			Dirty:           c.Memory.Filesystem.Dirty,
+			Writeback:       c.Memory.Filesystem.Writeback,
+			PageFaults:      c.Memory.PageCache.Fault,
+			MajorPageFaults: c.Memory.PageCache.MajorFault,
+		}
+	}
+	if c.PID != nil {
+		snapshot.PIDs = &common.PIDSnapshot{
+			Current: c.PID.Current,
+			Limit:   c.PID.Limit,
+		}
+	}
+	if c.IO != nil {
+		snapshot.IO = &common.IOSnapshot{
+			Devices: map[string]*common.IODeviceSnapshot{},
+		}
+		for deviceName, device := range c.IO.Devices {
+			snapshot.IO.Devices[deviceName] = &common.IODeviceSnapshot{
+				ReadBytes:  device.ReadBytes,
+				WriteBytes: device.WriteBytes,
+				ReadIOs:    device.ReadIOs,
+				WriteIOs:   device.WriteIOs,
+			}
+		}
+	}
+	if c.MemoryEvent != nil {
+		snapshot.Events = &common.EventSnapshot{
+			OomKills:   c.MemoryEvent.NumOomKillEvents,
+			OomEvents:  common.Uint64(c.MemoryEvent.NumOomEvents),
+			MemoryHigh: common.Uint64(c.MemoryEvent.High),
+			MemoryMax:  common.Uint64(c.MemoryEvent.Max),
+		}
+	}
+	if c.ProcStats != nil {
+		snapshot.Proc = &common.ProcSnapshot{
+			NumFDs: c.ProcStats.NumFD,
+		}
+		if closest := c.ProcStats.ClosestToFDLimit; closest != nil {
+			snapshot.Proc.FDLimitPercent = closest.UsagePercent()
+			snapshot.Proc.FDLimitPID = closest.PID
+		}
+	}
+	if c.Network != nil {
+		snapshot.Network = &common.NetworkSnapshot{
+			TCPSockets:                c.Network.TCPStats.Sockets,
+			UDPSockets:                c.Network.UDPStats.Sockets,
+			Namespaces:                len(c.Network.Namespaces),
+			HostNamespace:             c.Network.SharesHostNamespace,
+			RxBytes:                   c.Network.InterfaceTotal().RxBytes,
+			TxBytes:                   c.Network.InterfaceTotal().TxBytes,
+			UnknownNamespaceProcesses: c.Network.UnknownNamespaceProcesses(),
+		}
+		// The unknown namespace has an inode of zero, so that it is counted once when cgroups are summed, like any other
+		// namespace.
+		namespaces := slices.Clone(c.Network.Namespaces)
+		if c.Network.UnknownNamespace != nil {
+			namespaces = append(namespaces, c.Network.UnknownNamespace)
+		}
+		for _, namespace := range namespaces {
+			interfaces := namespace.InterfaceTotal()
+			snapshot.Network.ByNamespace = append(snapshot.Network.ByNamespace, &common.NetworkNamespaceSnapshot{
+				Inode:      namespace.Inode,
+				TCPSockets: namespace.TCPStats.Sockets,
+				UDPSockets: namespace.UDPStats.Sockets,
+				RxBytes:    interfaces.RxBytes,
+				TxBytes:    interfaces.TxBytes,
+			})
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// StatsProviderFn returns the stats for the cgroups which are listed by the interactive view.
+type StatsProviderFn func() (common.CgroupStatsCollection, error)
+
+// DetailProviderFn returns the stats for the single cgroup which the user drilled into.
+type DetailProviderFn func(name string) (common.CgroupStatsCollection, error)
+
+// App is an interactive, top-like view of cgroup stats. Rows can be selected with the arrow keys, sorted by any
+// column, collapsed into their parent, and drilled into to see verbose stats.
+type App struct {
+	statsProviderFn  StatsProviderFn
+	detailProviderFn DetailProviderFn
+	refreshInterval  time.Duration
+	terminal         *terminal
+
+	snapshots        []*common.CgroupSnapshot
+	rows             []*Row
+	detailCollection common.CgroupStatsCollection
+	lastUpdated      time.Time
+	err              error
+
+	// selectedName is used to keep the same cgroup selected when rows are reordered between samples.
+	selectedName   string
+	selected       int
+	offset         int
+	sortColumn     int
+	sortDescending bool
+	treeMode       bool
+	collapsed      map[string]bool
+	paused         bool
+	// detailName is the cgroup the user drilled into, or empty when the table is displayed.
+	detailName   string
+	detailOffset int
+}
+
+type AppOpt func(*App)
+
+func NewApp(statsProviderFn StatsProviderFn, detailProviderFn DetailProviderFn, refreshInterval time.Duration,
+	opts ...AppOpt) *App {
+	app := &App{
+		statsProviderFn:  statsProviderFn,
+		detailProviderFn: detailProviderFn,
+		refreshInterval:  refreshInterval,
+		sortColumn:       common.CPUColumn,
+		sortDescending:   true,
+		treeMode:         true,
+		collapsed:        map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+	return app
+}
+
+// WithSortKey sorts the table by the column of the given field and in the given order, rather than by CPU usage.
+func WithSortKey(key *common.SortKey) AppOpt {
+	return func(a *App) {
+		for i, column := range common.SnapshotColumns {
+			if column.Field == key.Field {
+				a.sortColumn = i
+				a.sortDescending = key.Descending
+				return
+			}
+		}
+	}
+}
+
+// WithFlatRows starts with the rows in sort order, rather than as a tree.
+func WithFlatRows() AppOpt {
+	return func(a *App) {
+		a.treeMode = false
+	}
+}
+
+func (a *App) Run() error {
+	t, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	a.terminal = t
+	defer func() {
+		_ = t.close()
+	}()
+
+	keys := make(chan KeyEvent)
+	go t.readKeys(keys)
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	ticker := time.NewTicker(a.refreshInterval)
+	defer ticker.Stop()
+
+	a.refresh()
+	a.render()
+	for {
+		select {
+		case <-ticker.C:
+			if !a.paused {
+				a.refresh()
+			}
+		case <-resize:
+		case key, ok := <-keys:
+			if !ok || a.handleKey(key) {
+				return nil
+			}
+		}
+		a.render()
+	}
+}
+
+// refresh collects a new sample of stats, either for all cgroups or for the cgroup the user drilled into.
+func (a *App) refresh() {
+	a.lastUpdated = time.Now()
+	if a.detailName != "" {
+		a.detailCollection, a.err = a.detailProviderFn(a.detailName)
+		return
+	}
+	collection, err := a.statsProviderFn()
+	a.err = err
+	if err != nil {
+		return
+	}
+	a.snapshots = collection.ToSnapshots()
+	a.buildRows()
+}
+
+// buildRows orders the latest snapshots according to the current sort and tree settings, and keeps the previously
+// selected cgroup selected.
+func (a *App) buildRows() {
+	column := common.SnapshotColumns[a.sortColumn]
+	a.rows = buildRows(a.snapshots, func(x, y *common.CgroupSnapshot) bool {
+		return less(column, x, y, a.sortDescending)
+	}, a.treeMode, a.collapsed)
+
+	a.selected = 0
+	for i, row := range a.rows {
+		if row.Snapshot.Name == a.selectedName {
+			a.selected = i
+			break
+		}
+	}
+	a.selectRow(a.selected)
+}
+
+func (a *App) selectRow(i int) {
+	if len(a.rows) == 0 {
+		a.selected = 0
+		a.selectedName = ""
+		return
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(a.rows) {
+		i = len(a.rows) - 1
+	}
+	a.selected = i
+	a.selectedName = a.rows[i].Snapshot.Name
+}
+
+// handleKey updates the state of the view for the given key press, and returns true if the application should exit.
+func (a *App) handleKey(event KeyEvent) bool {
+	if event.Key == KeyInterrupt || (event.Key == KeyRune && event.Rune == 'q') {
+		return true
+	}
+	if event.Key == KeyRune && event.Rune == 'p' {
+		a.paused = !a.paused
+		return false
+	}
+	if a.detailName != "" {
+		a.handleDetailKey(event)
+	} else {
+		a.handleTableKey(event)
+	}
+	return false
+}
+
+func (a *App) handleDetailKey(event KeyEvent) {
+	switch event.Key {
+	case KeyEscape, KeyBackspace, KeyLeft:
+		a.detailName = ""
+		a.detailCollection = nil
+		a.refresh()
+	case KeyUp:
+		if a.detailOffset > 0 {
+			a.detailOffset--
+		}
+	case KeyDown:
+		a.detailOffset++
+	}
+}
+
+func (a *App) handleTableKey(event KeyEvent) {
+	_, height := a.terminal.size()
+	pageSize := height - headerLines - footerLines
+	if pageSize < 1 {
+		pageSize = 1
+	}
+
+	switch event.Key {
+	case KeyUp:
+		a.selectRow(a.selected - 1)
+	case KeyDown:
+		a.selectRow(a.selected + 1)
+	case KeyPageUp:
+		a.selectRow(a.selected - pageSize)
+	case KeyPageDown:
+		a.selectRow(a.selected + pageSize)
+	case KeyHome:
+		a.selectRow(0)
+	case KeyEnd:
+		a.selectRow(len(a.rows) - 1)
+	case KeyEnter:
+		if a.selectedName != "" {
+			a.detailName = a.selectedName
+			a.detailOffset = 0
+			a.refresh()
+		}
+	case KeyLeft:
+		a.setSortColumn((a.sortColumn + len(common.SnapshotColumns) - 1) % len(common.SnapshotColumns))
+	case KeyRight:
+		a.setSortColumn((a.sortColumn + 1) % len(common.SnapshotColumns))
+	case KeyRune:
+		a.handleTableRune(event.Rune)
+	}
+}
+
+func (a *App) handleTableRune(r rune) {
+	switch {
+	case r >= '1' && r <= '9' && int(r-'1') < len(common.SnapshotColumns):
+		a.setSortColumn(int(r - '1'))
+	case r == 'r':
+		a.sortDescending = !a.sortDescending
+		a.buildRows()
+	case r == 't':
+		a.treeMode = !a.treeMode
+		a.buildRows()
+	case r == ' ':
+		if a.selectedName != "" && a.rows[a.selected].HasChildren {
+			a.collapsed[a.selectedName] = !a.collapsed[a.selectedName]
+			a.buildRows()
+		}
+	case r == 'k':
+		a.selectRow(a.selected - 1)
+	case r == 'j':
+		a.selectRow(a.selected + 1)
+	}
+}
+
+// setSortColumn sorts the table by the given column. Numeric columns are sorted in descending order so that the
+// largest consumers are shown first, while names are sorted alphabetically.
+func (a *App) setSortColumn(column int) {
+	a.sortColumn = column
+	a.sortDescending = common.SnapshotColumns[column].Field.Value != nil
+	a.buildRows()
+}
@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApp_WithSortKey(t *testing.T) {
+	// Given
+	app := NewApp(nil, nil, 0, WithSortKey(&common.SortKey{Field: common.MemoryField}), WithFlatRows())
+	app.snapshots = []*common.CgroupSnapshot{
+		{Name: "/a.service", CPU: &common.CPUSnapshot{Utilization: 50}, Memory: &common.MemorySnapshot{Usage: 300}},
+		{Name: "/b.service", CPU: &common.CPUSnapshot{Utilization: 10}, Memory: &common.MemorySnapshot{Usage: 100}},
+		{Name: "/c.service", CPU: &common.CPUSnapshot{Utilization: 30}, Memory: &common.MemorySnapshot{Usage: 200}},
+	}
+
+	// When
+	app.buildRows()
+
+	// Then
+	assert.Equal(t, "Mem Usage", common.SnapshotColumns[app.sortColumn].Header)
+	assert.False(t, app.sortDescending)
+	assert.Equal(t, []string{"/b.service", "/c.service", "/a.service"}, rowNames(app.rows),
+		"Rows should be in the order of the sort key rather than by CPU usage.")
+}
@@ -0,0 +1,94 @@
+package tui
+
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyInterrupt
+)
+
+type KeyEvent struct {
+	Key Key
+	// Rune is the character which was typed when Key is KeyRune.
+	Rune rune
+}
+
+// escapeSequences maps the escape sequences sent by the terminal to the keys they represent.
+var escapeSequences = map[string]Key{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// ParseKeys converts the raw bytes read from a terminal in raw mode into a list of key events. A lone escape byte is
+// reported as KeyEscape, and unknown escape sequences are dropped.
+func ParseKeys(input []byte) []KeyEvent {
+	var events []KeyEvent
+
+	for i := 0; i < len(input); {
+		b := input[i]
+		if b == 0x1b {
+			if i+1 == len(input) {
+				events = append(events, KeyEvent{Key: KeyEscape})
+				break
+			}
+			end := escapeSequenceEnd(input, i)
+			if key, ok := escapeSequences[string(input[i:end])]; ok {
+				events = append(events, KeyEvent{Key: key})
+			}
+			i = end
+			continue
+		}
+
+		switch b {
+		case '\r', '\n':
+			events = append(events, KeyEvent{Key: KeyEnter})
+		case 0x7f, 0x08:
+			events = append(events, KeyEvent{Key: KeyBackspace})
+		case 0x03:
+			events = append(events, KeyEvent{Key: KeyInterrupt})
+		default:
+			events = append(events, KeyEvent{Key: KeyRune, Rune: rune(b)})
+		}
+		i++
+	}
+	return events
+}
+
+// escapeSequenceEnd returns the index just past the escape sequence which starts at the given index. Sequences start
+// with ESC [ or ESC O, followed by optional parameters, and end with a letter or a tilde.
+func escapeSequenceEnd(input []byte, start int) int {
+	i := start + 1
+	if input[i] != '[' && input[i] != 'O' {
+		return i
+	}
+	for i++; i < len(input); i++ {
+		b := input[i]
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || b == '~' {
+			return i + 1
+		}
+	}
+	return len(input)
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ParseKeysTest struct {
+	input       string
+	expected    []KeyEvent
+	description string
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []*ParseKeysTest{
+		{input: "q", expected: []KeyEvent{{Key: KeyRune, Rune: 'q'}},
+			description: "Parse a single character."},
+		{input: "\x1b[A\x1b[B", expected: []KeyEvent{{Key: KeyUp}, {Key: KeyDown}},
+			description: "Parse multiple escape sequences."},
+		{input: "\x1b", expected: []KeyEvent{{Key: KeyEscape}},
+			description: "Parse a lone escape as the escape key."},
+		{input: "\x1b[6~\r", expected: []KeyEvent{{Key: KeyPageDown}, {Key: KeyEnter}},
+			description: "Parse an escape sequence ending with a tilde."},
+		{input: "\x1b[1;5Ap", expected: []KeyEvent{{Key: KeyRune, Rune: 'p'}},
+			description: "Drop unknown escape sequences."},
+		{input: "\x03", expected: []KeyEvent{{Key: KeyInterrupt}},
+			description: "Parse Ctrl-C as an interrupt."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseKeys([]byte(test.input)))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+const (
+	// headerLines is the number of lines above the rows of the table: the status line, a blank line and the headers.
+	headerLines = 3
+	// footerLines is the number of lines below the rows of the table: a blank line and the help line.
+	footerLines = 2
+	tabWidth    = 8
+
+	tableHelp  = "Up/Down: select  Left/Right/1-9: sort  r: reverse  Enter: details  Space: collapse  t: tree  p: pause  q: quit"
+	detailHelp = "Esc: back  Up/Down: scroll  p: pause  q: quit"
+)
+
+func (a *App) render() {
+	width, height := a.terminal.size()
+
+	var lines []string
+	if a.detailName != "" {
+		lines = a.renderDetail(height)
+	} else {
+		lines = a.renderTable(height)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(cursorHome)
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+		buf.WriteString(truncate(line, width))
+		buf.WriteString(resetStyle + clearLine)
+		if i < len(lines)-1 && i < height-1 {
+			buf.WriteString("\r\n")
+		}
+	}
+	buf.WriteString(clearToEnd)
+	_, _ = a.terminal.out.Write(buf.Bytes())
+}
+
+func (a *App) statusLine(title string) string {
+	status := fmt.Sprintf("%s  sort: %s (%s)  updated: %s", title, common.SnapshotColumns[a.sortColumn].Header,
+		sortOrder(a.sortDescending), a.lastUpdated.Format("15:04:05"))
+	if a.paused {
+		status += "  [PAUSED]"
+	}
+	if a.err != nil {
+		status += fmt.Sprintf("  error: %s", a.err)
+	}
+	return bold + status + resetStyle
+}
+
+func sortOrder(descending bool) string {
+	if descending {
+		return "desc"
+	}
+	return "asc"
+}
+
+func sortIndicator(descending bool) string {
+	if descending {
+		return " v"
+	}
+	return " ^"
+}
+
+func (a *App) renderTable(height int) []string {
+	cells := make([][]string, 0, len(a.rows)+1)
+	headers := make([]string, len(common.SnapshotColumns))
+	for i, column := range common.SnapshotColumns {
+		headers[i] = column.Header
+		if i == a.sortColumn {
+			headers[i] += sortIndicator(a.sortDescending)
+		}
+	}
+	cells = append(cells, headers)
+	for _, row := range a.rows {
+		rowCells := make([]string, len(common.SnapshotColumns))
+		rowCells[common.NameColumn] = displayName(row, a.treeMode)
+		for i := common.NameColumn + 1; i < len(common.SnapshotColumns); i++ {
+			rowCells[i] = common.SnapshotColumns[i].Format(row.Snapshot)
+		}
+		cells = append(cells, rowCells)
+	}
+	formatted := formatCells(cells)
+
+	// Scroll so that the selected row is always visible.
+	pageSize := height - headerLines - footerLines
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	if a.selected < a.offset {
+		a.offset = a.selected
+	}
+	if a.selected >= a.offset+pageSize {
+		a.offset = a.selected - pageSize + 1
+	}
+	if a.offset > len(a.rows)-pageSize {
+		a.offset = max(len(a.rows)-pageSize, 0)
+	}
+
+	lines := []string{a.statusLine("cgstat"), "", bold + formatted[0] + resetStyle}
+	for i := a.offset; i < len(a.rows) && i < a.offset+pageSize; i++ {
+		line := formatted[i+1]
+		if i == a.selected {
+			line = reverseVideo + line
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, tableHelp)
+}
+
+func (a *App) renderDetail(height int) []string {
+	var buf bytes.Buffer
+	if a.detailCollection != nil {
+		a.detailCollection.ToVerboseOutput(&buf)
+	}
+	detail := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, line := range detail {
+		detail[i] = expandTabs(line)
+	}
+
+	pageSize := height - headerLines - footerLines + 1
+	if a.detailOffset > len(detail)-pageSize {
+		a.detailOffset = max(len(detail)-pageSize, 0)
+	}
+
+	lines := []string{a.statusLine("cgstat: " + a.detailName), ""}
+	for i := a.detailOffset; i < len(detail) && i < a.detailOffset+pageSize; i++ {
+		lines = append(lines, detail[i])
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, detailHelp)
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, since tabs are not expanded by the terminal in raw
+// mode.
+func expandTabs(line string) string {
+	var buf strings.Builder
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := tabWidth - column%tabWidth
+			buf.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+		buf.WriteRune(r)
+		column++
+	}
+	return buf.String()
+}
+
+// formatCells pads each cell so that the columns are aligned, and returns one line per row.
+func formatCells(cells [][]string) []string {
+	var widths []int
+	for _, row := range cells {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	lines := make([]string, 0, len(cells))
+	for _, row := range cells {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-len(cell)+2))
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// truncate shortens the given line to the width of the terminal, ignoring escape sequences used for styling.
+func truncate(line string, width int) string {
+	var buf strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range line {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
+				inEscape = false
+			}
+		default:
+			if visible >= width {
+				continue
+			}
+			visible++
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearToEnd     = "\x1b[J"
+	reverseVideo   = "\x1b[7m"
+	bold           = "\x1b[1m"
+	resetStyle     = "\x1b[0m"
+
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// terminal puts the controlling terminal into raw mode so that individual key presses can be read, and draws to the
+// alternate screen so that the previous contents of the terminal are restored on exit.
+type terminal struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+}
+
+func openTerminal() (*terminal, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, errors.New("interactive mode requires a terminal")
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	t := &terminal{
+		in:    os.Stdin,
+		out:   os.Stdout,
+		state: state,
+	}
+	_, _ = t.out.WriteString(enterAltScreen + hideCursor)
+
+	return t, nil
+}
+
+func (t *terminal) close() error {
+	_, _ = t.out.WriteString(showCursor + exitAltScreen)
+	return term.Restore(int(t.in.Fd()), t.state)
+}
+
+func (t *terminal) size() (int, int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+	return width, height
+}
+
+// readKeys reads from the terminal until it is closed, and sends each key press to the given channel.
+func (t *terminal) readKeys(keys chan<- KeyEvent) {
+	buf := make([]byte, 64)
+	for {
+		n, err := t.in.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, event := range ParseKeys(buf[:n]) {
+			keys <- event
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// Row is a single visible row of the interactive table.
+type Row struct {
+	Snapshot *common.CgroupSnapshot
+	// Depth is the number of displayed ancestors of the cgroup. It is always zero outside of tree mode.
+	Depth int
+	// HasChildren is true if other displayed cgroups are nested under this one.
+	HasChildren bool
+	Collapsed   bool
+}
+
+// buildRows orders the given snapshots for display. In tree mode, each cgroup is nested under its closest ancestor
+// which is also part of the snapshots, siblings are sorted, and the descendants of collapsed cgroups are hidden.
+func buildRows(snapshots []*common.CgroupSnapshot, lessFn func(a, b *common.CgroupSnapshot) bool, treeMode bool,
+	collapsed map[string]bool) []*Row {
+	if !treeMode {
+		sorted := make([]*common.CgroupSnapshot, len(snapshots))
+		copy(sorted, snapshots)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return lessFn(sorted[i], sorted[j])
+		})
+		rows := make([]*Row, 0, len(sorted))
+		for _, snapshot := range sorted {
+			rows = append(rows, &Row{Snapshot: snapshot})
+		}
+		return rows
+	}
+
+	names := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		names[i] = snapshot.Name
+	}
+	entries := common.BuildTree(names, func(i, j int) bool {
+		return lessFn(snapshots[i], snapshots[j])
+	}, -1, collapsed)
+
+	rows := make([]*Row, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, &Row{
+			Snapshot:    snapshots[entry.Index],
+			Depth:       entry.Depth,
+			HasChildren: entry.HasChildren,
+			Collapsed:   collapsed[entry.Name],
+		})
+	}
+	return rows
+}
+
+// displayName returns the name shown for a row. In tree mode the name is indented by depth, and prefixed with a
+// marker showing whether the row can be expanded or collapsed.
+func displayName(row *Row, treeMode bool) string {
+	if !treeMode {
+		return row.Snapshot.Name
+	}
+	marker := "  "
+	if row.HasChildren && row.Collapsed {
+		marker = "+ "
+	} else if row.HasChildren {
+		marker = "- "
+	}
+	indent := strings.Repeat("  ", row.Depth)
+	name := common.TreeDisplayName(&common.TreeEntry{Name: row.Snapshot.Name, Depth: row.Depth})
+	return indent + marker + strings.TrimPrefix(name, indent)
+}
+
+// less returns true if the first snapshot should be ordered before the second one when sorting by the given column.
+func less(column *common.SnapshotColumn, a *common.CgroupSnapshot, b *common.CgroupSnapshot, descending bool) bool {
+	cmp := column.Field.Compare(a, b)
+	if cmp == 0 {
+		return a.Name < b.Name
+	}
+	if descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
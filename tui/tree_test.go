@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSnapshot(name string, cpu float64) *common.CgroupSnapshot {
+	return &common.CgroupSnapshot{Name: name, CPU: &common.CPUSnapshot{Utilization: cpu}}
+}
+
+func rowNames(rows []*Row) []string {
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.Snapshot.Name)
+	}
+	return names
+}
+
+func TestBuildRows(t *testing.T) {
+	// Given
+	snapshots := []*common.CgroupSnapshot{
+		newSnapshot("/system.slice", 10),
+		newSnapshot("/system.slice/a.service", 1),
+		newSnapshot("/system.slice/b.service", 5),
+		newSnapshot("/system.slice/b.service/child", 3),
+		newSnapshot("/user.slice", 20),
+	}
+	byCPU := func(a, b *common.CgroupSnapshot) bool {
+		return less(common.SnapshotColumns[common.CPUColumn], a, b, true)
+	}
+
+	t.Run("Sort all rows in flat mode.", func(t *testing.T) {
+		rows := buildRows(snapshots, byCPU, false, map[string]bool{})
+
+		assert.Equal(t, []string{"/user.slice", "/system.slice", "/system.slice/b.service",
+			"/system.slice/b.service/child", "/system.slice/a.service"}, rowNames(rows))
+	})
+
+	t.Run("Sort siblings under their parent in tree mode.", func(t *testing.T) {
+		rows := buildRows(snapshots, byCPU, true, map[string]bool{})
+
+		assert.Equal(t, []string{"/user.slice", "/system.slice", "/system.slice/b.service",
+			"/system.slice/b.service/child", "/system.slice/a.service"}, rowNames(rows))
+		assert.Equal(t, 2, rows[3].Depth)
+		assert.True(t, rows[1].HasChildren)
+	})
+
+	t.Run("Hide the descendants of collapsed cgroups.", func(t *testing.T) {
+		rows := buildRows(snapshots, byCPU, true, map[string]bool{"/system.slice/b.service": true})
+
+		assert.Equal(t, []string{"/user.slice", "/system.slice", "/system.slice/b.service",
+			"/system.slice/a.service"}, rowNames(rows))
+		assert.Equal(t, "  + b.service", displayName(rows[2], true))
+	})
+}
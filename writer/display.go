@@ -16,6 +16,9 @@ const (
 // CgStatsDisplayWriter will display stats for a set of cgroups to the screen
 type CgStatsDisplayWriter struct {
 	writer *uilive.Writer
+	// treeMode replaces the name of each cgroup with its name indented by its depth in the cgroup hierarchy. The
+	// collection is expected to already be ordered as a tree.
+	treeMode bool
 }
 
 func NewCgStatsDisplayWriter() StatsWriter {
@@ -27,8 +30,23 @@ func NewCgStatsDisplayWriter() StatsWriter {
 	}
 }
 
+// NewCgStatsTreeDisplayWriter will display stats for a set of cgroups as a tree, with each cgroup indented under its
+// parent.
+func NewCgStatsTreeDisplayWriter() StatsWriter {
+	writer := uilive.New()
+	writer.Start()
+
+	return &CgStatsDisplayWriter{
+		writer:   writer,
+		treeMode: true,
+	}
+}
+
 func (c *CgStatsDisplayWriter) Write(cgroupStats common.CgroupStatsCollection) error {
 	displayOutput := cgroupStats.ToDisplayOutput()
+	if c.treeMode {
+		withTreeNames(displayOutput, cgroupStats.ToSnapshots())
+	}
 
 	tbl := table.New(displayOutput.Headers...)
 	tbl.WithWriter(c.writer)
@@ -39,3 +57,18 @@ func (c *CgStatsDisplayWriter) Write(cgroupStats common.CgroupStatsCollection) e
 	tbl.Print()
 	return c.writer.Flush()
 }
+
+// withTreeNames replaces the name column of each row with the cgroup name indented by its depth in the tree.
+func withTreeNames(displayOutput *common.DisplayOutput, snapshots []*common.CgroupSnapshot) {
+	names := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		names[i] = snapshot.Name
+	}
+	// The collection is already in tree order, so the original order is kept.
+	entries := common.BuildTree(names, func(i, j int) bool {
+		return i < j
+	}, -1, nil)
+	for _, entry := range entries {
+		displayOutput.Rows[entry.Index][0] = common.TreeDisplayName(entry)
+	}
+}
@@ -0,0 +1,43 @@
+package writer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/strategicpause/cgstat/stats/common"
+)
+
+// CgroupStatsJSONWriter is an implementation of StatsWriter which will write each sample of cgroup stats as an
+// indented JSON document.
+type CgroupStatsJSONWriter struct {
+	encoder *json.Encoder
+}
+
+func NewCgroupStatsJSONWriter(w io.Writer) StatsWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return &CgroupStatsJSONWriter{
+		encoder: encoder,
+	}
+}
+
+func (c *CgroupStatsJSONWriter) Write(collection common.CgroupStatsCollection) error {
+	return c.encoder.Encode(collection.ToJSONOutput())
+}
+
+// CgroupStatsNDJSONWriter is an implementation of StatsWriter which will write each sample of cgroup stats as a single
+// line of JSON, so that a stream of samples can be consumed line by line.
+type CgroupStatsNDJSONWriter struct {
+	encoder *json.Encoder
+}
+
+func NewCgroupStatsNDJSONWriter(w io.Writer) StatsWriter {
+	return &CgroupStatsNDJSONWriter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (c *CgroupStatsNDJSONWriter) Write(collection common.CgroupStatsCollection) error {
+	return c.encoder.Encode(collection.ToJSONOutput())
+}
@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/strategicpause/cgstat/stats/common"
+	v2 "github.com/strategicpause/cgstat/stats/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonSample is a sample of the JSON writers, decoded with the v2 model.
+type jsonSample struct {
+	Timestamp     time.Time
+	CgroupVersion string
+	Cgroups       []*v2.CgroupStats
+}
+
+func newJSONCollection() common.CgroupStatsCollection {
+	return v2.NewCollection([]*v2.CgroupStats{
+		{
+			Name: "/system.slice/a.service",
+			CPU:  &v2.CPUStats{UsageInUsec: 9_007_199_254_740_993, Utilization: 12.5},
+			Memory: &v2.MemoryStats{
+				Anon: &v2.AnonymousMemoryStats{Total: 4096},
+			},
+			IO: &v2.IOStats{Devices: map[string]*v2.IODeviceStats{
+				"nvme0n1": {Major: 259, ReadBytes: 1 << 40, ReadBytesPerSec: 1024.5},
+			}},
+		},
+	})
+}
+
+func TestCgroupStatsJSONWriter(t *testing.T) {
+	// Given
+	var output bytes.Buffer
+	writer := NewCgroupStatsJSONWriter(&output)
+
+	// When
+	assert.NoError(t, writer.Write(newJSONCollection()))
+	assert.NoError(t, writer.Write(newJSONCollection()))
+
+	// Then
+	decoder := json.NewDecoder(&output)
+	for i := 0; i < 2; i++ {
+		var sample jsonSample
+		assert.NoError(t, decoder.Decode(&sample), "Each sample should be a separate JSON document.")
+		assertJSONSample(t, sample)
+	}
+	assert.False(t, decoder.More())
+}
+
+func TestCgroupStatsNDJSONWriter(t *testing.T) {
+	// Given
+	var output bytes.Buffer
+	writer := NewCgroupStatsNDJSONWriter(&output)
+
+	// When
+	assert.NoError(t, writer.Write(newJSONCollection()))
+	assert.NoError(t, writer.Write(newJSONCollection()))
+
+	// Then
+	scanner := bufio.NewScanner(&output)
+	lines := 0
+	for scanner.Scan() {
+		var sample jsonSample
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &sample), "Each line should be a JSON document.")
+		assertJSONSample(t, sample)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func assertJSONSample(t *testing.T, sample jsonSample) {
+	assert.Equal(t, common.CgroupV2, sample.CgroupVersion)
+	assert.Len(t, sample.Cgroups, 1)
+	cgroupStats := sample.Cgroups[0]
+	assert.Equal(t, "/system.slice/a.service", cgroupStats.Name)
+	assert.Equal(t, uint64(9_007_199_254_740_993), cgroupStats.CPU.UsageInUsec,
+		"Counters should keep their raw value rather than being formatted.")
+	assert.Equal(t, 12.5, cgroupStats.CPU.Utilization)
+	assert.Equal(t, uint64(4096), cgroupStats.Memory.Anon.Total)
+	assert.Equal(t, uint64(1<<40), cgroupStats.IO.Devices["nvme0n1"].ReadBytes)
+	assert.Equal(t, 1024.5, cgroupStats.IO.Devices["nvme0n1"].ReadBytesPerSec)
+}
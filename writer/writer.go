@@ -2,6 +2,7 @@ package writer
 
 import (
 	"fmt"
+	"os"
 )
 
 func WithCSVWriter(filename string) ViewWriterOptions {
@@ -19,6 +20,24 @@ func WithDisplayWriter(verbosity DisplayVerbosity) ViewWriterOptions {
 	}
 }
 
+func WithTreeDisplayWriter() ViewWriterOptions {
+	return func() (StatsWriter, error) {
+		return NewCgStatsTreeDisplayWriter(), nil
+	}
+}
+
+func WithJSONWriter() ViewWriterOptions {
+	return func() (StatsWriter, error) {
+		return NewCgroupStatsJSONWriter(os.Stdout), nil
+	}
+}
+
+func WithNDJSONWriter() ViewWriterOptions {
+	return func() (StatsWriter, error) {
+		return NewCgroupStatsNDJSONWriter(os.Stdout), nil
+	}
+}
+
 func NewViewWriters(options []ViewWriterOptions) []StatsWriter {
 	var writers []StatsWriter
 	for _, opt := range options {